@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jredh-dev/divine-academy/internal/game"
+	"github.com/jredh-dev/divine-academy/internal/story"
+)
+
+// ProgressView is the player's experience tracks and unlocked abilities
+type ProgressView struct {
+	Tracks    []TrackView
+	Abilities []string // Names of unlocked abilities
+}
+
+// TrackView is one experience track
+type TrackView struct {
+	Name   string
+	XP     int
+	Into   int // Experience toward the next ability point
+	Max    int // Experience per ability point
+	Points int // Unspent ability points
+}
+
+// AbilityMenu is the list of abilities offered on an ability scene
+type AbilityMenu struct {
+	Options    []AbilityView
+	Unlockable bool // At least one option can be unlocked now
+}
+
+// AbilityView is an ability as offered to a particular player
+type AbilityView struct {
+	game.Ability
+	Owned      bool
+	Affordable bool // Not owned, and the player has a point in its track
+}
+
+// progressView describes the player's progress, or nil before any is made
+func (a *app) progressView(state *game.GameState) *ProgressView {
+	p := &state.Progress
+	if len(p.XP) == 0 && len(p.Abilities) == 0 {
+		return nil
+	}
+
+	view := &ProgressView{}
+	for _, track := range game.Tracks {
+		view.Tracks = append(view.Tracks, TrackView{
+			Name:   trackName(track),
+			XP:     p.XP[track],
+			Into:   game.AbilityPointXP - p.ToNextPoint(track),
+			Max:    game.AbilityPointXP,
+			Points: p.Points(track),
+		})
+	}
+	for _, id := range p.Abilities {
+		view.Abilities = append(view.Abilities, a.abilityName(id))
+	}
+	return view
+}
+
+// abilityMenu describes the options on an ability scene, or nil for other scenes
+func abilityMenu(scene *story.Scene, state *game.GameState) *AbilityMenu {
+	if scene.ThreadType != story.ThreadAbility {
+		return nil
+	}
+	menu := &AbilityMenu{}
+	for _, ability := range scene.Abilities {
+		view := AbilityView{Ability: ability, Owned: state.HasAbility(ability.ID)}
+		view.Affordable = !view.Owned && state.Progress.Points(ability.Track) > 0
+		menu.Unlockable = menu.Unlockable || view.Affordable
+		menu.Options = append(menu.Options, view)
+	}
+	return menu
+}
+
+// offeredAbility finds an ability offered on a scene
+func offeredAbility(scene *story.Scene, id string) (game.Ability, bool) {
+	for _, ability := range scene.Abilities {
+		if ability.ID == id {
+			return ability, true
+		}
+	}
+	return game.Ability{}, false
+}
+
+// abilityName looks up an ability's display name in the catalogue
+func (a *app) abilityName(id string) string {
+	if ability, ok := a.scenes.Abilities().Ability(id); ok {
+		return ability.Name
+	}
+	return id
+}
+
+// pointsNote announces ability points earned in each track
+func pointsNote(points map[game.KeywordCategory]int) string {
+	var parts []string
+	for _, track := range game.Tracks {
+		if n := points[track]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s %s", n, track, plural(n, "ability point", "ability points")))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "You earned " + strings.Join(parts, " and ") + "!"
+}
+
+// trackName capitalises a track for display
+func trackName(track game.KeywordCategory) string {
+	s := string(track)
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
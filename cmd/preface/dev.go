@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/jredh-dev/divine-academy/internal/story"
+)
+
+// ReloadErrorData is shown in place of the game while scene files are invalid
+type ReloadErrorData struct {
+	Dir         string
+	Diagnostics story.Diagnostics
+	Message     string // Used when the error has no positioned diagnostics
+}
+
+// devReloader hot-reloads the scenes directory and remembers the last failure
+type devReloader struct {
+	dir    string
+	scenes *story.SceneRepository
+
+	mu  sync.RWMutex
+	err error // Most recent load error, nil once the scenes validate again
+}
+
+// newDevReloader loads scenes, keeping the server up even if the first load fails
+// Until the files are fixed the repository is empty and the error page is shown
+func newDevReloader(dir string, scenes *story.SceneRepository) *devReloader {
+	d := &devReloader{dir: dir, scenes: scenes}
+	d.reload()
+	return d
+}
+
+// reload re-validates the scenes and swaps them in if they are valid
+func (d *devReloader) reload() bool {
+	err := d.scenes.Reload()
+
+	d.mu.Lock()
+	d.err = err
+	d.mu.Unlock()
+
+	if err != nil {
+		log.Printf("⚠️  Scene reload failed, keeping previous scenes:\n%v", err)
+		return false
+	}
+	log.Printf("🔄 Reloaded %d scenes from %s", len(d.scenes.Scenes()), d.dir)
+	return true
+}
+
+// Err returns the error from the most recent reload
+func (d *devReloader) Err() error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.err
+}
+
+// watch reloads whenever the scenes directory changes, until ctx is cancelled
+func (d *devReloader) watch(ctx context.Context) error {
+	watcher, err := story.NewWatcher(d.dir, story.DefaultPollInterval)
+	if err != nil {
+		return err
+	}
+	go watcher.Run(ctx, func() { d.reload() }, func(err error) {
+		log.Printf("Scene watcher: %v", err)
+	})
+	return nil
+}
+
+// guard shows the validation errors instead of the game while the scenes are invalid
+func (d *devReloader) guard(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := d.Err()
+		if err == nil {
+			next(w, r)
+			return
+		}
+
+		data := ReloadErrorData{Dir: d.dir, Diagnostics: story.AsDiagnostics(err)}
+		if len(data.Diagnostics) == 0 {
+			data.Message = err.Error()
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		if err := templates.ExecuteTemplate(w, "reload_error.html", data); err != nil {
+			log.Printf("Template error: %v", err)
+		}
+	}
+}
+
+// handleDevGraph serves the scene graph as a DOT or Mermaid flowchart
+func (a *app) handleDevGraph(w http.ResponseWriter, r *http.Request) {
+	var write func(io.Writer, []story.Scene) error
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "mermaid":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		write = story.WriteMermaid
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		write = story.WriteDOT
+	default:
+		http.Error(w, "Unknown format (use dot or mermaid)", http.StatusBadRequest)
+		return
+	}
+
+	if err := write(w, a.scenes.Scenes()); err != nil {
+		log.Printf("Graph export error: %v", err)
+	}
+}
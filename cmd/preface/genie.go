@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jredh-dev/divine-academy/internal/game"
+	"github.com/jredh-dev/divine-academy/internal/genie"
+	"github.com/jredh-dev/divine-academy/internal/story"
+)
+
+// BottleView is the genie's state on a scene, rendered as the bottle in
+// scene.html and returned as JSON by /hint
+type BottleView struct {
+	Hints     []string `json:"hints"`     // Tiers revealed so far on this scene
+	Remaining int      `json:"remaining"` // Tiers not yet revealed
+	Ready     bool     `json:"ready"`     // The bottle can be rubbed now
+	Wait      int      `json:"wait"`      // Seconds until the genie is rested
+	Status    string   `json:"status"`
+}
+
+// bottleView describes the genie for a scene, or nil if the scene has no hints
+func (a *app) bottleView(scene *story.Scene, state *game.GameState) *BottleView {
+	if len(scene.Hints) == 0 {
+		return nil
+	}
+
+	shown := min(state.Hints[scene.ID], len(scene.Hints))
+	wait := state.Genie.Wait(time.Now())
+	view := &BottleView{
+		Hints:     scene.Hints[:shown],
+		Remaining: len(scene.Hints) - shown,
+	}
+	if view.Remaining > 0 {
+		view.Ready = wait == 0
+		view.Wait = int((wait + time.Second - 1) / time.Second)
+	}
+
+	switch free := state.Genie.FreeLeft(a.hints); {
+	case view.Remaining == 0:
+		view.Status = "The genie has told you all it knows about this."
+	case wait > 0:
+		view.Status = fmt.Sprintf("The genie is resting. Ready in %s.", genie.Describe(wait))
+	case free > 0:
+		view.Status = fmt.Sprintf("%d free %s left.", free, plural(free, "rub", "rubs"))
+	default:
+		view.Status = fmt.Sprintf("Rubbing now rests the genie for %s.", genie.Describe(state.Genie.NextCooldown(a.hints)))
+	}
+	if view.Ready && scene.Weighted() {
+		view.Status += " A hint caps this question at a B."
+	}
+	return view
+}
+
+// handleHint rubs the bottle to reveal the next hint tier for the current scene
+// Scripts get the bottle as JSON; plain form posts are sent back to the scene
+func (a *app) handleHint(w http.ResponseWriter, r *http.Request) {
+	scene, state, ok := a.currentScene(w, r)
+	if !ok {
+		return
+	}
+	if len(scene.Hints) == 0 {
+		http.Error(w, "The genie has nothing to say about this scene", http.StatusNotFound)
+		return
+	}
+
+	status := http.StatusOK
+	switch {
+	case state.Hints[scene.ID] >= len(scene.Hints):
+		status = http.StatusConflict
+	case state.Genie.Rub(a.hints, time.Now()) != nil:
+		status = http.StatusTooManyRequests // Still resting
+	default:
+		state.RevealHint(scene.ID)
+		if !a.saveState(w, state) {
+			return
+		}
+	}
+
+	if !wantsJSON(r) {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	writeJSON(w, a.bottleView(scene, state))
+}
+
+// wantsJSON reports whether the request came from a script expecting JSON
+func wantsJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// plural picks the singular or plural form of a word for n
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
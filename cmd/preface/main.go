@@ -1,38 +1,121 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"os"
+	"slices"
 	"strconv"
+	"strings"
 
+	"github.com/jredh-dev/divine-academy/internal/game"
+	"github.com/jredh-dev/divine-academy/internal/genie"
+	"github.com/jredh-dev/divine-academy/internal/grading"
+	"github.com/jredh-dev/divine-academy/internal/impact"
+	"github.com/jredh-dev/divine-academy/internal/session"
 	"github.com/jredh-dev/divine-academy/internal/story"
 )
 
 type PageData struct {
-	Scene    *story.Scene
-	Feedback string
+	Scene      *story.Scene
+	Choices    []ChoiceView // Choices available to this player
+	Feedback   string
+	Attributes impact.Store
+	Result     *game.ValidationResult // Outcome of a rejected open response
+	Draft      string                 // Player's previous answer, restored on retry
+	Bottle     *BottleView            // Genie hints for the scene (nil if it has none)
+	Abilities  *AbilityMenu           // Options on an ability scene
+	Progress   *ProgressView          // Experience tracks (nil until the player has some)
 }
 
+// ChoiceView is a choice as offered to a particular player
+type ChoiceView struct {
+	Index int // Position in Scene.Choices, submitted as choice_index
+	Text  string
+	Gated bool // Unlocked by a condition; styled as a hidden option
+}
+
+// startSceneID is where every new game begins
+const startSceneID = "preface.0:dream-start"
+
+// stateCookie holds the player's encrypted GameState
+const stateCookie = "game"
+
+// templatePattern finds the page templates, relative to the working directory
+const templatePattern = "web/templates/*.html"
+
 var templates *template.Template
 
-func init() {
-	templates = template.Must(template.ParseGlob("web/templates/*.html"))
+// app holds the dependencies shared by the HTTP handlers
+type app struct {
+	scenes   *story.SceneRepository
+	sessions *session.Codec // Encrypts and signs game state cookies
+	hints    genie.Schedule // Cooldowns between genie hints
 }
 
 func main() {
-	// Load scenes on startup (will panic if validation fails)
-	story.GetPrefaceScenes()
-	fmt.Println("✅ Scene graph validated successfully")
+	templates = template.Must(template.ParseGlob(templatePattern))
+
+	dev := flag.Bool("dev", false, "enable development mode: hot-reload scenes and serve /dev/graph")
+	hints := flag.String("hints", genie.DefaultSchedule.String(), "genie hint cooldown schedule: 2x, 3x, or 5x")
+	flag.Parse()
+
+	schedule, err := genie.ParseSchedule(*hints)
+	if err != nil {
+		log.Fatal(err)
+	}
+	a := &app{scenes: story.NewSceneRepository(story.DirLoader(story.ScenesDir, story.LoadOptions{Start: startSceneID})), hints: schedule}
+
+	// In dev mode every game route shows scene errors instead of playing
+	route := func(h http.HandlerFunc) http.HandlerFunc { return h }
+	if *dev {
+		reloader := newDevReloader(story.ScenesDir, a.scenes)
+		if err := reloader.watch(context.Background()); err != nil {
+			log.Fatalf("Failed to watch scenes: %v", err)
+		}
+		route = reloader.guard
+		fmt.Printf("👀 Watching %s for changes\n", story.ScenesDir)
+	} else {
+		// Refuse to start with an invalid story
+		if err := a.scenes.Reload(); err != nil {
+			log.Fatalf("Failed to load scenes: %v", err)
+		}
+		fmt.Println("✅ Scene graph validated successfully")
+	}
+
+	if secret := os.Getenv("SESSION_SECRET"); secret != "" {
+		a.sessions, err = session.NewCodec([]byte(secret))
+	} else {
+		log.Println("SESSION_SECRET not set; using a random key (sessions reset on restart)")
+		a.sessions, err = session.NewRandomCodec()
+	}
+	if err != nil {
+		log.Fatalf("Failed to initialise sessions: %v", err)
+	}
 
 	// Serve static files (CSS, JS, images)
 	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("web/static"))))
 
 	// Main routes
-	http.HandleFunc("/", handleHome)
-	http.HandleFunc("/scene", handleScene)
-	http.HandleFunc("/choice", handleChoice)
+	http.HandleFunc("/", route(a.handleHome))
+	http.HandleFunc("/scene", route(a.handleScene))
+	http.HandleFunc("/choice", route(a.handleChoice))
+	http.HandleFunc("/restart", route(a.handleRestart))
+	http.HandleFunc("/finisher", route(a.handleFinisher))
+	http.HandleFunc("/validate", route(a.handleValidate))
+	http.HandleFunc("/hint", route(a.handleHint))
+
+	// Development routes expose the whole story, so they are opt-in
+	if *dev {
+		http.HandleFunc("/dev/graph", a.handleDevGraph)
+		log.Println("Development routes enabled: /dev/graph?format=dot|mermaid")
+	}
 
 	port := ":8080"
 	fmt.Printf("\n🎮 Writing Project Preface running at http://localhost%s\n\n", port)
@@ -43,34 +126,99 @@ func main() {
 	log.Fatal(http.ListenAndServe(port, nil))
 }
 
-func handleHome(w http.ResponseWriter, r *http.Request) {
-	// Start with the first scene
-	scene := story.GetScene("preface.0:dream-start")
+func (a *app) handleHome(w http.ResponseWriter, r *http.Request) {
+	// Resume an existing game, or start a new one
+	state, ok := a.loadState(r)
+	if !ok {
+		if state, ok = a.newGame(w); !ok {
+			return
+		}
+		if !a.saveState(w, state) {
+			return
+		}
+	}
+
+	if state.Finished() {
+		renderReportCard(w, a.reportCard(state))
+		return
+	}
+
+	scene := a.scenes.Scene(state.CurrentScene)
 	if scene == nil {
-		http.Error(w, "Starting scene not found", http.StatusNotFound)
+		http.Error(w, "Current scene not found", http.StatusNotFound)
 		return
 	}
 
-	renderScene(w, scene, "")
+	a.renderScene(w, scene, "", state)
 }
 
-func handleScene(w http.ResponseWriter, r *http.Request) {
+func (a *app) handleScene(w http.ResponseWriter, r *http.Request) {
 	sceneID := r.URL.Query().Get("id")
 	if sceneID == "" {
 		http.Error(w, "Missing scene ID", http.StatusBadRequest)
 		return
 	}
 
-	scene := story.GetScene(sceneID)
+	state, ok := a.loadState(r)
+	if !ok {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	// Players may only view the scene they are currently on
+	if !state.IsCurrent(sceneID) {
+		http.Error(w, "Scene not available", http.StatusForbidden)
+		return
+	}
+
+	scene := a.scenes.Scene(sceneID)
 	if scene == nil {
 		http.Error(w, "Scene not found", http.StatusNotFound)
 		return
 	}
 
-	renderScene(w, scene, "")
+	a.renderScene(w, scene, "", state)
 }
 
-func handleChoice(w http.ResponseWriter, r *http.Request) {
+func (a *app) handleRestart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Best grades survive a restart so players can replay to improve them
+	previous, ok := a.loadState(r)
+	if !ok || len(previous.Grades) == 0 {
+		a.sessions.Clear(w, stateCookie)
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	state, ok := a.newGame(w)
+	if !ok {
+		return
+	}
+	state.Grades = previous.Grades
+	if !a.saveState(w, state) {
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// newGame creates a state at the starting scene
+// On failure it writes an error response and returns false
+func (a *app) newGame(w http.ResponseWriter) (*game.GameState, bool) {
+	start := a.scenes.Scene(startSceneID)
+	if start == nil {
+		http.Error(w, "Starting scene not found", http.StatusNotFound)
+		return nil, false
+	}
+	state := game.NewGameState(start.ID)
+	state.Learn(start.Teaches...)
+	return state, true
+}
+
+func (a *app) handleChoice(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -85,7 +233,19 @@ func handleChoice(w http.ResponseWriter, r *http.Request) {
 	choiceIndexStr := r.FormValue("choice_index")
 	userText := r.FormValue("user_text") // For open responses
 
-	currentScene := story.GetScene(sceneID)
+	state, ok := a.loadState(r)
+	if !ok {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	// Reject submissions for scenes the player is not on (stale tabs, forged forms)
+	if !state.IsCurrent(sceneID) {
+		http.Error(w, "That scene is no longer active", http.StatusConflict)
+		return
+	}
+
+	currentScene := a.scenes.Scene(sceneID)
 	if currentScene == nil {
 		http.Error(w, "Current scene not found", http.StatusNotFound)
 		return
@@ -93,6 +253,7 @@ func handleChoice(w http.ResponseWriter, r *http.Request) {
 
 	var nextSceneID string
 	var feedback string
+	rewards := currentScene.Rewards
 
 	switch currentScene.ThreadType {
 	case story.ThreadMulti:
@@ -104,51 +265,363 @@ func handleChoice(w http.ResponseWriter, r *http.Request) {
 		}
 
 		choice := currentScene.Choices[choiceIndex]
-		nextSceneID = choice.Next
+		if !choice.Available(state) {
+			http.Error(w, "That choice is not available", http.StatusForbidden)
+			return
+		}
 		feedback = fmt.Sprintf("You chose: %s", choice.Text)
 
-		// TODO: Process impact (choice.Impact) when attribute system is implemented
+		state.RecordChoice(currentScene.ID, choiceIndex)
+		if err := state.ApplyImpact(choice.Impact); err != nil {
+			// Impacts are validated at load time, so this indicates a bug
+			log.Printf("Scene %s choice %d: %v", currentScene.ID, choiceIndex, err)
+		}
+		state.TakeApproach(choice.Approach)
+		rewards = rewards.Add(choice.Rewards).Add(choice.Approach.Rewards())
+
+		// Route after applying the impact and karma so branches can react to them
+		nextSceneID = choice.NextFor(state)
+
+	case story.ThreadMany:
+		selected, err := parseSelection(r.Form["choice_index"], len(currentScene.Choices))
+		if err != nil {
+			http.Error(w, "Invalid choice", http.StatusBadRequest)
+			return
+		}
+		if len(selected) == 0 {
+			a.renderScene(w, currentScene, "Select at least one answer.", state)
+			return
+		}
+
+		result := currentScene.Selection.ValidateChoice(selected)
+		feedback = selectionFeedback(result)
+
+		for _, i := range selected {
+			state.RecordChoice(currentScene.ID, i)
+			if err := state.ApplyImpact(currentScene.Choices[i].Impact); err != nil {
+				log.Printf("Scene %s choice %d: %v", currentScene.ID, i, err)
+			}
+		}
+		state.RecordAnswer(currentScene.ID, grading.Answer{Score: result.Score})
+		nextSceneID = currentScene.NextFor(state)
 
 	case story.ThreadOpen:
 		// Validate open response
 		if len(userText) < currentScene.MinLength {
 			// Re-render current scene with error
-			renderScene(w, currentScene, fmt.Sprintf("Please provide at least %d characters.", currentScene.MinLength))
+			a.renderRetry(w, currentScene, fmt.Sprintf("Please provide at least %d characters.", currentScene.MinLength), state, nil, userText)
 			return
 		}
-		nextSceneID = currentScene.Next
 		feedback = "Response recorded."
 
-	case story.ThreadAffirmative, story.ThreadFinisher:
+		if currentScene.Validator != nil {
+			result := currentScene.Validator.Validate(userText)
+			// Retries are unlimited, so the first attempt's score is the grade
+			state.RecordAttempt(currentScene.ID, grading.Answer{Score: result.Score, Spelling: len(result.FuzzyMatches)})
+			if !result.Correct {
+				message := "Not quite. Take another look and try again."
+				if result.Rejection != "" {
+					message = result.Rejection.Message()
+				}
+				if !a.saveState(w, state) {
+					return
+				}
+				a.renderRetry(w, currentScene, message, state, &result, userText)
+				return
+			}
+			feedback = "Correct!"
+			if len(result.FuzzyMatches) > 0 {
+				feedback = "Correct! Check your spelling: " + spellingNote(result.FuzzyMatches)
+			}
+		}
+
+		state.RecordResponse(currentScene.ID, userText)
+		nextSceneID = currentScene.NextFor(state)
+
+	case story.ThreadFinisher:
+		// Without JavaScript the passage arrives here in one go
+		result := currentScene.Finisher.Validate(userText)
+		// Retries are unlimited, so the first attempt's score is the grade
+		state.RecordAttempt(currentScene.ID, grading.Answer{Score: result.Score})
+		if !result.Correct {
+			if !a.saveState(w, state) {
+				return
+			}
+			a.renderRetry(w, currentScene, "Not finished yet. Check the highlighted words and keep going.", state, &result, userText)
+			return
+		}
+		feedback = "Well done!"
+
+		state.RecordResponse(currentScene.ID, userText)
+		nextSceneID = currentScene.NextFor(state)
+
+	case story.ThreadAffirmative:
 		// Simple continue
-		nextSceneID = currentScene.Next
+		nextSceneID = currentScene.NextFor(state)
 		feedback = ""
 
+	case story.ThreadAbility:
+		// Skipping keeps the points for a later ability scene, even if an
+		// ability was selected before the player changed their mind
+		if id := r.FormValue("ability"); id != "" && r.FormValue("skip") == "" {
+			ability, ok := offeredAbility(currentScene, id)
+			if !ok {
+				http.Error(w, "Invalid ability", http.StatusBadRequest)
+				return
+			}
+			if err := state.UnlockAbility(ability); err != nil {
+				a.renderScene(w, currentScene, fmt.Sprintf("You can't unlock %s right now.", ability.Name), state)
+				return
+			}
+			feedback = fmt.Sprintf("You unlocked %s!", ability.Name)
+		} else {
+			feedback = "You save your ability points for later."
+		}
+		nextSceneID = currentScene.NextFor(state)
+
 	default:
 		http.Error(w, "Invalid thread type", http.StatusInternalServerError)
 		return
 	}
 
+	// Scenes that count toward the grade reward experience in proportion to the score
+	if currentScene.Weighted() {
+		rewards = rewards.Scale(state.Answers[currentScene.ID].Score)
+	}
+	if note := pointsNote(state.GrantRewards(currentScene.ID, rewards)); note != "" {
+		feedback = strings.TrimSpace(feedback + " " + note)
+	}
+
+	// Grade the chapter as the player leaves it
+	if chapter := story.ChapterOf(currentScene.ID); story.ChapterOf(nextSceneID) != chapter {
+		a.gradeChapter(state, chapter)
+	}
+
 	// Check for terminal scene
 	if nextSceneID == "0" {
-		renderEndScreen(w)
+		state.Enter(nextSceneID)
+		if a.saveState(w, state) {
+			renderReportCard(w, a.reportCard(state))
+		}
 		return
 	}
 
 	// Get next scene
-	nextScene := story.GetScene(nextSceneID)
+	nextScene := a.scenes.Scene(nextSceneID)
 	if nextScene == nil {
 		http.Error(w, "Next scene not found", http.StatusNotFound)
 		return
 	}
 
-	renderScene(w, nextScene, feedback)
+	state.Enter(nextScene.ID)
+	state.Learn(nextScene.Teaches...)
+	if !a.saveState(w, state) {
+		return
+	}
+
+	a.renderScene(w, nextScene, feedback, state)
+}
+
+// parseSelection reads the checked boxes of a many-choice form
+// Repeated indices are dropped; any index out of range is an error
+func parseSelection(values []string, choices int) ([]int, error) {
+	selected := make([]int, 0, len(values))
+	for _, v := range values {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 || i >= choices {
+			return nil, fmt.Errorf("invalid choice %q", v)
+		}
+		if !slices.Contains(selected, i) {
+			selected = append(selected, i)
+		}
+	}
+	return selected, nil
+}
+
+// selectionFeedback describes a many-choice result without revealing the answers
+func selectionFeedback(result game.ValidationResult) string {
+	switch {
+	case result.Correct:
+		return "Correct! You found every right answer."
+	case result.Score > 0:
+		return fmt.Sprintf("Partly right: you found %.0f%% of the right answers.", result.Score*100)
+	default:
+		return "Not quite. At least one of your picks was wrong."
+	}
+}
+
+// spellingNote lists misspelled words alongside their correct spelling
+func spellingNote(matches []game.FuzzyMatch) string {
+	notes := make([]string, len(matches))
+	for i, m := range matches {
+		notes[i] = fmt.Sprintf("%q should be %q", m.Input, m.Keyword)
+	}
+	return strings.Join(notes, ", ")
+}
+
+// DraftFeedback is live feedback on an open response that is still being written
+// It reports how close the draft is without naming the keywords it lacks
+type DraftFeedback struct {
+	Correct   bool                 `json:"correct"`
+	Score     float64              `json:"score"`               // 0.0 to 1.0, drives the textarea glow
+	Matched   int                  `json:"matched"`             // Keywords found in the draft
+	Missing   int                  `json:"missing"`             // Keywords not yet found
+	Fuzzy     int                  `json:"fuzzy"`               // Matches that are misspelled
+	Rejection game.RejectionReason `json:"rejection,omitempty"` // Why a numeric draft is not accepted
+	Annotated template.HTML        `json:"annotated"`
+}
+
+// handleValidate runs an open scene's validator against a draft and returns
+// the result as JSON, so the page can react while the player types
+func (a *app) handleValidate(w http.ResponseWriter, r *http.Request) {
+	scene, ok := a.activeScene(w, r, story.ThreadOpen)
+	if !ok {
+		return
+	}
+
+	draft := r.FormValue("user_text")
+	if scene.Validator == nil {
+		// Only the length is checked, so progress is how much has been written
+		feedback := DraftFeedback{Correct: len(draft) >= scene.MinLength, Score: 1}
+		if !feedback.Correct {
+			feedback.Score = float64(len(draft)) / float64(scene.MinLength)
+		}
+		writeJSON(w, feedback)
+		return
+	}
+
+	result := scene.Validator.Validate(draft)
+	writeJSON(w, DraftFeedback{
+		Correct:   result.Correct && len(draft) >= scene.MinLength,
+		Score:     result.Score,
+		Matched:   len(result.MatchedWords),
+		Missing:   len(result.MissingWords),
+		Fuzzy:     len(result.FuzzyMatches),
+		Rejection: result.Rejection,
+		Annotated: result.AnnotatedInput,
+	})
+}
+
+// handleFinisher checks a partially typed finisher passage and returns
+// per-token correctness as JSON, for live feedback while the player types
+func (a *app) handleFinisher(w http.ResponseWriter, r *http.Request) {
+	scene, ok := a.activeScene(w, r, story.ThreadFinisher)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, scene.Finisher.Check(r.FormValue("user_text")))
+}
+
+// activeScene resolves the scene_id posted to a live feedback endpoint
+// The scene must be the player's current one and of the given thread type
+// On failure it writes an error response and returns false
+func (a *app) activeScene(w http.ResponseWriter, r *http.Request, threadType story.ThreadType) (*story.Scene, bool) {
+	scene, _, ok := a.currentScene(w, r)
+	if !ok {
+		return nil, false
+	}
+	if scene.ThreadType != threadType {
+		http.Error(w, fmt.Sprintf("Not a %s scene", threadType), http.StatusBadRequest)
+		return nil, false
+	}
+	return scene, true
+}
+
+// currentScene checks a POST is for the scene the player is on and returns
+// it with the player's state
+// On failure it writes an error response and returns false
+func (a *app) currentScene(w http.ResponseWriter, r *http.Request) (*story.Scene, *game.GameState, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return nil, nil, false
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	state, ok := a.loadState(r)
+	if !ok {
+		http.Error(w, "No active game", http.StatusUnauthorized)
+		return nil, nil, false
+	}
+
+	sceneID := r.FormValue("scene_id")
+	if !state.IsCurrent(sceneID) {
+		http.Error(w, "That scene is no longer active", http.StatusConflict)
+		return nil, nil, false
+	}
+
+	scene := a.scenes.Scene(sceneID)
+	if scene == nil {
+		http.Error(w, "Scene not found", http.StatusNotFound)
+		return nil, nil, false
+	}
+	return scene, state, true
+}
+
+// writeJSON sends v as a JSON response
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("JSON encode error: %v", err)
+	}
 }
 
-func renderScene(w http.ResponseWriter, scene *story.Scene, feedback string) {
+// loadState reads the player's game state from the session cookie
+// Returns false if there is no valid session
+func (a *app) loadState(r *http.Request) (*game.GameState, bool) {
+	var state game.GameState
+	if err := a.sessions.Read(r, stateCookie, &state); err != nil {
+		if !errors.Is(err, http.ErrNoCookie) {
+			log.Printf("Discarding session: %v", err)
+		}
+		return nil, false
+	}
+	if state.CurrentScene == "" {
+		return nil, false
+	}
+	if state.Attributes == nil {
+		state.Attributes = impact.NewStore()
+	}
+	return &state, true
+}
+
+// saveState writes the game state to the session cookie
+// On failure it writes an error response and returns false
+func (a *app) saveState(w http.ResponseWriter, state *game.GameState) bool {
+	err := a.sessions.Write(w, stateCookie, state)
+	// A long game outgrows the cookie; forget old history rather than the game
+	for errors.Is(err, session.ErrTooLarge) && state.Trim() {
+		err = a.sessions.Write(w, stateCookie, state)
+	}
+	if err != nil {
+		log.Printf("Failed to save session: %v", err)
+		http.Error(w, "Failed to save progress", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+func (a *app) renderScene(w http.ResponseWriter, scene *story.Scene, feedback string, state *game.GameState) {
+	a.renderRetry(w, scene, feedback, state, nil, "")
+}
+
+// renderRetry re-renders a scene after a rejected answer, showing the
+// validation result and restoring the player's draft
+func (a *app) renderRetry(w http.ResponseWriter, scene *story.Scene, feedback string, state *game.GameState, result *game.ValidationResult, draft string) {
 	data := PageData{
-		Scene:    scene,
-		Feedback: feedback,
+		Scene:      scene,
+		Choices:    availableChoices(scene, state),
+		Feedback:   feedback,
+		Attributes: state.Attributes,
+		Result:     result,
+		Draft:      draft,
+		Bottle:     a.bottleView(scene, state),
+		Abilities:  abilityMenu(scene, state),
+		Progress:   a.progressView(state),
 	}
 
 	if err := templates.ExecuteTemplate(w, "scene.html", data); err != nil {
@@ -157,26 +630,19 @@ func renderScene(w http.ResponseWriter, scene *story.Scene, feedback string) {
 	}
 }
 
-func renderEndScreen(w http.ResponseWriter) {
-	fmt.Fprintf(w, `
-	<!DOCTYPE html>
-	<html>
-	<head>
-		<title>Demo Complete</title>
-		<link rel="stylesheet" href="/static/css/main.css">
-	</head>
-	<body>
-		<main class="scene-container">
-			<header><h1>Writing Project: Preface</h1></header>
-			<article class="scene">
-				<h2>Demo Complete!</h2>
-				<p>You've reached the end of the current demo.</p>
-				<p>The full preface will have many more scenes!</p>
-				<br>
-				<a href="/" style="color: #667eea; font-weight: bold;">Start Over</a>
-			</article>
-		</main>
-	</body>
-	</html>
-	`)
+// availableChoices filters a scene's choices down to those the player may pick
+func availableChoices(scene *story.Scene, state *game.GameState) []ChoiceView {
+	views := make([]ChoiceView, 0, len(scene.Choices))
+	for i := range scene.Choices {
+		choice := &scene.Choices[i]
+		if !choice.Available(state) {
+			continue
+		}
+		views = append(views, ChoiceView{
+			Index: i,
+			Text:  choice.Text,
+			Gated: choice.Gated(),
+		})
+	}
+	return views
 }
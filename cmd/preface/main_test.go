@@ -0,0 +1,361 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jredh-dev/divine-academy/internal/game"
+	"github.com/jredh-dev/divine-academy/internal/genie"
+	"github.com/jredh-dev/divine-academy/internal/grading"
+	"github.com/jredh-dev/divine-academy/internal/session"
+	"github.com/jredh-dev/divine-academy/internal/story"
+)
+
+func TestMain(m *testing.M) {
+	// Tests run in cmd/preface, two levels below the templates
+	templates = template.Must(template.ParseGlob(filepath.Join("..", "..", templatePattern)))
+	os.Exit(m.Run())
+}
+
+// newTestApp serves the scenes in a YAML document, with an optional
+// ability catalogue
+func newTestApp(t *testing.T, scenes, abilities string) *app {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "preface.yaml")
+	if err := os.WriteFile(path, []byte(scenes), 0o644); err != nil {
+		t.Fatalf("Failed to write scenes: %v", err)
+	}
+	var catalogue *story.Catalogue
+	if abilities != "" {
+		catalogue = loadTestCatalogue(t, filepath.Join(dir, story.AbilitiesFile), abilities)
+	}
+	loaded, err := story.LoadScenesFromYAML(path)
+	if err != nil {
+		t.Fatalf("Failed to load scenes: %v", err)
+	}
+	codec, err := session.NewRandomCodec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &app{scenes: story.NewStaticRepository(loaded, catalogue), sessions: codec, hints: genie.DefaultSchedule}
+}
+
+// loadTestCatalogue writes an ability catalogue to path and loads it
+func loadTestCatalogue(t *testing.T, path, abilities string) *story.Catalogue {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(abilities), 0o644); err != nil {
+		t.Fatalf("Failed to write abilities: %v", err)
+	}
+	catalogue, err := story.LoadCatalogue(path)
+	if err != nil {
+		t.Fatalf("Failed to load abilities: %v", err)
+	}
+	return catalogue
+}
+
+// player is one browser playing against a test app, keeping its cookies
+// between requests
+type player struct {
+	t       *testing.T
+	a       *app
+	cookies map[string]*http.Cookie
+}
+
+// newPlayer starts a new game at startSceneID
+func newPlayer(t *testing.T, a *app) *player {
+	t.Helper()
+	p := &player{t: t, a: a, cookies: map[string]*http.Cookie{}}
+	if code := p.do(a.handleHome, http.MethodGet, nil).Code; code != http.StatusOK {
+		t.Fatalf("GET / = %d, want 200", code)
+	}
+	return p
+}
+
+// do sends a request to a handler and keeps any cookies it sets
+func (p *player) do(h http.HandlerFunc, method string, form url.Values) *httptest.ResponseRecorder {
+	p.t.Helper()
+	req := httptest.NewRequest(method, "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for _, c := range p.cookies {
+		req.AddCookie(c)
+	}
+	rec := httptest.NewRecorder()
+	h(rec, req)
+	for _, c := range rec.Result().Cookies() {
+		p.cookies[c.Name] = c
+	}
+	return rec
+}
+
+// choose posts a form for the player's current scene to /choice
+func (p *player) choose(form url.Values) *httptest.ResponseRecorder {
+	p.t.Helper()
+	form.Set("scene_id", p.state().CurrentScene)
+	rec := p.do(p.a.handleChoice, http.MethodPost, form)
+	if rec.Code != http.StatusOK {
+		p.t.Fatalf("POST /choice %v = %d: %s", form, rec.Code, rec.Body)
+	}
+	return rec
+}
+
+// state decodes the player's saved game
+func (p *player) state() *game.GameState {
+	p.t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range p.cookies {
+		req.AddCookie(c)
+	}
+	state, ok := p.a.loadState(req)
+	if !ok {
+		p.t.Fatal("No saved game")
+	}
+	return state
+}
+
+const openQuestionScenes = `
+scenes:
+  - id: preface.0:dream-start
+    thread_type: open
+    text: Name both professors.
+    validation:
+      keywords: [aldwin, sera]
+      required_count: 1
+      partial: true
+    next: 0
+`
+
+func TestHandleChoice_OpenAnswerScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		answers []string
+		want    grading.Letter
+	}{
+		{"complete", []string{"Aldwin and Sera"}, grading.S},
+		{"partial", []string{"Aldwin"}, grading.F},
+		{"retried after a wrong answer", []string{"I don't know", "Aldwin and Sera"}, grading.F},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPlayer(t, newTestApp(t, openQuestionScenes, ""))
+			for _, answer := range tt.answers {
+				p.choose(url.Values{"user_text": {answer}})
+			}
+
+			state := p.state()
+			if !state.Finished() {
+				t.Fatalf("current scene = %s, want the game finished", state.CurrentScene)
+			}
+			if got := state.Grades["preface"]; got != tt.want {
+				t.Errorf("preface grade = %s, want %s (answer %+v)", got, tt.want, state.Answers["preface.0:dream-start"])
+			}
+		})
+	}
+}
+
+const finisherScenes = `
+scenes:
+  - id: preface.0:dream-start
+    thread_type: finisher
+    text: Recite the motto.
+    target: Knowledge shared is power multiplied.
+    next: 0
+`
+
+func TestHandleChoice_FinisherRetry(t *testing.T) {
+	p := newPlayer(t, newTestApp(t, finisherScenes, ""))
+	rec := p.choose(url.Values{"user_text": {"Knowledge shared is power"}})
+	if !strings.Contains(rec.Body.String(), "Not finished yet") {
+		t.Fatal("expected an unfinished passage to be retried")
+	}
+	if p.state().Finished() {
+		t.Fatal("game finished on an unfinished passage")
+	}
+
+	p.choose(url.Values{"user_text": {"Knowledge shared is power multiplied."}})
+	state := p.state()
+	if !state.Finished() {
+		t.Fatalf("current scene = %s, want the game finished", state.CurrentScene)
+	}
+	if got := state.Answers["preface.0:dream-start"].Score; got >= 1 {
+		t.Errorf("score = %v, want the first attempt's score below 1", got)
+	}
+}
+
+// longGameScenes writes a story of n scenes in chapters of chapterSize,
+// mixing choices with open questions, each ending in the next scene
+// IDs are as long as the real story's, since a whole chapter is kept.
+func longGameScenes(n, chapterSize int) string {
+	id := func(i int) string {
+		switch {
+		case i == 0:
+			return startSceneID
+		case i == n:
+			return "0"
+		case i < chapterSize:
+			return fmt.Sprintf("preface.%d:campus-library-tour", i)
+		}
+		return fmt.Sprintf("chapter%d.%d:campus-library-tour", i/chapterSize, i%chapterSize)
+	}
+
+	var b strings.Builder
+	b.WriteString("scenes:\n")
+	for i := range n {
+		fmt.Fprintf(&b, "  - id: %s\n    text: Scene %d\n", id(i), i)
+		if i%4 == 3 {
+			fmt.Fprintf(&b, `    thread_type: open
+    validation:
+      keywords: [courage]
+    hints: [Be brave.]
+    rewards:
+      emotional: 5
+    next: %s
+`, id(i+1))
+			continue
+		}
+		fmt.Fprintf(&b, `    thread_type: multi
+    choices:
+      - text: Help
+        next: %[1]s
+        impact: player.kindness+1
+        approach: empathize
+        rewards:
+          emotional: 3
+      - text: Compete
+        next: %[1]s
+        impact: player.pride+1
+        approach: relate
+        rewards:
+          emotional: 3
+`, id(i+1))
+	}
+	return b.String()
+}
+
+func TestHandleChoice_LongGameFitsCookie(t *testing.T) {
+	const scenes = 200
+	a := newTestApp(t, longGameScenes(scenes, 20), "")
+	p := newPlayer(t, a)
+
+	for i := 0; !p.state().Finished(); i++ {
+		if i >= scenes {
+			t.Fatalf("game still running after %d scenes", i)
+		}
+		scene := a.scenes.Scene(p.state().CurrentScene)
+		if scene.ThreadType == story.ThreadOpen {
+			p.do(a.handleHint, http.MethodPost, url.Values{"scene_id": {scene.ID}})
+			p.choose(url.Values{"user_text": {"Courage, always."}})
+		} else {
+			p.choose(url.Values{"choice_index": {strconv.Itoa(i % 2)}})
+		}
+
+		if size := len(p.cookies[stateCookie].Value); size > session.MaxCookieSize {
+			t.Fatalf("after scene %d the cookie is %d bytes, over %d", i, size, session.MaxCookieSize)
+		}
+	}
+
+	state := p.state()
+	if got, want := len(state.Played), scenes/20; got != want {
+		t.Errorf("%d chapters graded on this playthrough, want %d", got, want)
+	}
+	if len(state.Answers) == len(state.Played)*5 {
+		t.Error("expected answers in earlier chapters to be trimmed")
+	}
+	if got, want := len(a.reportCard(state).Chapters), scenes/20; got != want {
+		t.Errorf("report card has %d chapters, want %d", got, want)
+	}
+	if !state.HasVisited("0") {
+		t.Error("expected the end of the game to be remembered")
+	}
+}
+
+const abilityScenes = `
+scenes:
+  - id: preface.0:dream-start
+    thread_type: multi
+    text: Study hard?
+    choices:
+      - text: Yes
+        next: preface.1:first-ability
+        rewards:
+          mental: 10
+  - id: preface.1:first-ability
+    thread_type: ability
+    text: Pick an ability.
+    abilities: [division]
+    next: preface.2:end
+  - id: preface.2:end
+    thread_type: affirmative
+    text: The end.
+    next: 0
+`
+
+const abilityCatalogue = `
+abilities:
+  - id: division
+    name: Division
+    track: mental
+`
+
+func TestHandleChoice_Ability(t *testing.T) {
+	tests := []struct {
+		name         string
+		form         url.Values
+		wantUnlock   bool
+		wantFeedback string
+	}{
+		{"unlock", url.Values{"ability": {"division"}}, true, "You unlocked Division!"},
+		{"skip", url.Values{"skip": {"1"}}, false, "You save your ability points"},
+		{"skip with an ability selected", url.Values{"ability": {"division"}, "skip": {"1"}}, false, "You save your ability points"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newPlayer(t, newTestApp(t, abilityScenes, abilityCatalogue))
+			p.choose(url.Values{"choice_index": {"0"}})
+			if got := p.state().Progress.Points(game.Mental); got != 1 {
+				t.Fatalf("mental points = %d, want 1", got)
+			}
+
+			rec := p.choose(tt.form)
+			if !strings.Contains(rec.Body.String(), tt.wantFeedback) {
+				t.Errorf("response does not contain %q", tt.wantFeedback)
+			}
+			state := p.state()
+			if got := state.HasAbility("division"); got != tt.wantUnlock {
+				t.Errorf("HasAbility(division) = %v, want %v", got, tt.wantUnlock)
+			}
+			if got, want := state.Progress.Points(game.Mental), 1; !tt.wantUnlock && got != want {
+				t.Errorf("mental points = %d, want %d saved", got, want)
+			}
+		})
+	}
+}
+
+func TestProgressView_AbilityNames(t *testing.T) {
+	// Multiplication is in the catalogue but no scene offers it
+	a := newTestApp(t, abilityScenes, abilityCatalogue+`
+  - id: multiplication
+    name: Multiplication
+    track: mental
+`)
+	state := game.NewGameState(startSceneID)
+	state.Progress.XP = map[game.KeywordCategory]int{game.Mental: 20}
+	state.Progress.Abilities = []string{"division", "multiplication", "retired"}
+
+	got := a.progressView(state).Abilities
+	want := []string{"Division", "Multiplication", "retired"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ability names = %v, want %v", got, want)
+	}
+}
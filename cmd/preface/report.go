@@ -0,0 +1,125 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/jredh-dev/divine-academy/internal/game"
+	"github.com/jredh-dev/divine-academy/internal/grading"
+	"github.com/jredh-dev/divine-academy/internal/story"
+)
+
+// ReportCard is shown when the player reaches the end of the story
+type ReportCard struct {
+	Chapters []ChapterReport // Graded chapters in the order they were played
+}
+
+// ChapterReport is one chapter's line on the report card
+type ChapterReport struct {
+	Chapter   string
+	Grade     grading.Grade
+	Best      grading.Letter // Best grade ever earned in the chapter, including this one
+	Questions []QuestionReport
+}
+
+// QuestionReport is one graded question on the report card
+type QuestionReport struct {
+	Title    string
+	Percent  float64 // Score after any hint cap, 0 to 100
+	Weight   float64
+	Hinted   bool
+	Spelling int
+}
+
+// chapterQuestions collects the graded questions the player answered in a chapter
+func (a *app) chapterQuestions(state *game.GameState, chapter string) []grading.Question {
+	var questions []grading.Question
+	for _, scene := range a.scenes.Scenes() {
+		if story.ChapterOf(scene.ID) != chapter || !scene.Graded() {
+			continue
+		}
+		answer, ok := state.Answers[scene.ID]
+		if !ok {
+			continue // Not on the player's path
+		}
+		questions = append(questions, grading.Question{SceneID: scene.ID, Weight: scene.Weight, Answer: answer})
+	}
+	return questions
+}
+
+// gradeChapter grades a chapter the player has just left and keeps the grade
+// if it is their best
+func (a *app) gradeChapter(state *game.GameState, chapter string) {
+	grade := grading.Calculate(a.chapterQuestions(state, chapter))
+	if grade.Total == 0 {
+		return
+	}
+	if state.RecordGrade(chapter, grade) {
+		log.Printf("New best grade in %s: %s (%.0f%%)", chapter, grade.Letter, grade.Percent)
+	}
+}
+
+// reportCard grades every chapter the player visited on this playthrough,
+// in story order
+// A chapter whose answers were trimmed from the session reports the grade
+// kept when the player left it.
+func (a *app) reportCard(state *game.GameState) ReportCard {
+	var card ReportCard
+	visited := make(map[string]bool)
+	for _, id := range state.Visited {
+		visited[story.ChapterOf(id)] = true
+	}
+	seen := make(map[string]bool)
+	for _, scene := range a.scenes.Scenes() {
+		chapter := story.ChapterOf(scene.ID)
+		played, graded := state.Played[chapter]
+		if seen[chapter] || !visited[chapter] && !graded {
+			continue
+		}
+		seen[chapter] = true
+
+		questions := a.chapterQuestions(state, chapter)
+		grade := grading.Calculate(questions)
+		if grade.Total == 0 {
+			grade = played
+		}
+		if grade.Total == 0 {
+			continue
+		}
+
+		report := ChapterReport{Chapter: chapter, Grade: grade, Best: state.Grades[chapter]}
+		if grade.Letter.Better(report.Best) {
+			report.Best = grade.Letter
+		}
+		for _, q := range questions {
+			if q.Weight <= 0 {
+				continue
+			}
+			report.Questions = append(report.Questions, QuestionReport{
+				Title:    sceneTitle(q.SceneID),
+				Percent:  100 * q.Answer.Capped(),
+				Weight:   q.Weight,
+				Hinted:   q.Answer.Hinted,
+				Spelling: q.Answer.Spelling,
+			})
+		}
+		card.Chapters = append(card.Chapters, report)
+	}
+	return card
+}
+
+// sceneTitle turns a scene ID such as "preface.3:tutorial-open" into "tutorial open"
+func sceneTitle(id string) string {
+	if _, desc, ok := strings.Cut(id, ":"); ok {
+		return strings.ReplaceAll(desc, "-", " ")
+	}
+	return id
+}
+
+func renderReportCard(w http.ResponseWriter, card ReportCard) {
+	if err := templates.ExecuteTemplate(w, "report_card.html", card); err != nil {
+		log.Printf("Template error: %v", err)
+		http.Error(w, "Template error", http.StatusInternalServerError)
+	}
+}
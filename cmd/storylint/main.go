@@ -0,0 +1,204 @@
+// Command storylint validates and analyses scene files without starting the game.
+//
+// Usage:
+//
+//	go run ./cmd/storylint [-format text|json] [-mode strict|warn] [-start scene-id] [-stats] [dir]
+//	go run ./cmd/storylint graph [-format dot|mermaid] [dir]
+//
+// The graph subcommand prints the scene graph as a Graphviz or Mermaid flowchart.
+// It exits with status 1 if any errors are found and 2 on bad usage.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jredh-dev/divine-academy/internal/story"
+)
+
+// Report is the JSON output of a lint run
+type Report struct {
+	OK          bool              `json:"ok"`
+	Errors      int               `json:"errors"`
+	Warnings    int               `json:"warnings"`
+	Diagnostics story.Diagnostics `json:"diagnostics"`
+	Stats       *story.Stats      `json:"stats,omitempty"`
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run lints a scenes directory and returns the process exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "graph" {
+		return runGraph(args[1:], stdout, stderr)
+	}
+
+	flags := flag.NewFlagSet("storylint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", "text", "output format: text or json")
+	modeName := flags.String("mode", "strict", "graph analysis mode: strict (findings are errors) or warn")
+	start := flags.String("start", "", "entry scene for reachability (default: first scene)")
+	showStats := flags.Bool("stats", true, "report scene statistics")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: storylint [flags] [scenes-dir]")
+		fmt.Fprintln(stderr, "       storylint graph [-format dot|mermaid] [scenes-dir]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "storylint: invalid format '%s' (must be text or json)\n", *format)
+		return 2
+	}
+	mode, err := story.ParseValidationMode(*modeName)
+	if err != nil {
+		fmt.Fprintf(stderr, "storylint: %v\n", err)
+		return 2
+	}
+
+	dir := story.ScenesDir
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		dir = flags.Arg(0)
+	default:
+		flags.Usage()
+		return 2
+	}
+
+	scenes, warnings, err := story.LoadScenesFromDirWithOptions(dir, story.LoadOptions{Mode: mode, Start: *start})
+
+	report := Report{Diagnostics: story.Diagnostics{}}
+	report.Diagnostics = append(report.Diagnostics, story.AsDiagnostics(err)...)
+	report.Diagnostics = append(report.Diagnostics, warnings...)
+	for _, d := range report.Diagnostics {
+		if d.Severity == story.SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	report.OK = report.Errors == 0
+	if report.OK && *showStats {
+		stats := story.ComputeStats(scenes, *start)
+		report.Stats = &stats
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(stderr, "storylint: %v\n", err)
+			return 2
+		}
+	} else {
+		writeText(stdout, dir, report)
+	}
+
+	if !report.OK {
+		return 1
+	}
+	return 0
+}
+
+// runGraph exports a scenes directory as a flowchart and returns the process exit code
+func runGraph(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("storylint graph", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", "dot", "output format: dot or mermaid")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: storylint graph [-format dot|mermaid] [scenes-dir]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var write func(io.Writer, []story.Scene) error
+	switch *format {
+	case "dot":
+		write = story.WriteDOT
+	case "mermaid":
+		write = story.WriteMermaid
+	default:
+		fmt.Fprintf(stderr, "storylint: invalid format '%s' (must be dot or mermaid)\n", *format)
+		return 2
+	}
+
+	dir := story.ScenesDir
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		dir = flags.Arg(0)
+	default:
+		flags.Usage()
+		return 2
+	}
+
+	// Unreachable scenes and closed cycles are worth seeing, so only errors stop the export
+	scenes, _, err := story.LoadScenesFromDirWithOptions(dir, story.LoadOptions{Mode: story.ModeWarn})
+	if err != nil {
+		fmt.Fprintf(stderr, "storylint: %v\n", err)
+		return 1
+	}
+
+	if err := write(stdout, scenes); err != nil {
+		fmt.Fprintf(stderr, "storylint: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// writeText prints a report for humans
+func writeText(w io.Writer, dir string, report Report) {
+	for _, d := range report.Diagnostics {
+		fmt.Fprintf(w, "%s: %s\n", d.Severity, d)
+	}
+
+	if report.OK {
+		fmt.Fprintf(w, "✅ %s: scene graph is valid (%d warning(s))\n", dir, report.Warnings)
+	} else {
+		fmt.Fprintf(w, "❌ %s: %d error(s), %d warning(s)\n", dir, report.Errors, report.Warnings)
+	}
+
+	if report.Stats == nil {
+		return
+	}
+	s := report.Stats
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Scenes:         %d\n", s.Scenes)
+	fmt.Fprintf(w, "By thread type: %s\n", formatCounts(s.ByThreadType))
+	fmt.Fprintf(w, "Choices:        %d\n", s.Choices)
+	fmt.Fprintf(w, "Branching:      %.2f average, %d max\n", s.AvgBranching, s.MaxBranching)
+	fmt.Fprintf(w, "Path length:    %d shortest, %d longest (scenes)\n", s.ShortestPath, s.LongestPath)
+	fmt.Fprintln(w, "Chapters:")
+	for _, c := range s.Chapters {
+		fmt.Fprintf(w, "  %-12s %3d scenes %6d words\n", c.Name, c.Scenes, c.Words)
+	}
+}
+
+// formatCounts renders thread type counts in a stable order
+func formatCounts(counts map[story.ThreadType]int) string {
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+
+	parts := make([]string, len(types))
+	for i, t := range types {
+		parts[i] = fmt.Sprintf("%s=%d", t, counts[story.ThreadType(t)])
+	}
+	return strings.Join(parts, ", ")
+}
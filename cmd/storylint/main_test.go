@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jredh-dev/divine-academy/internal/story"
+)
+
+const cleanScenes = `
+scenes:
+  - id: preface.0:start
+    thread_type: multi
+    text: Welcome to the academy.
+    choices:
+      - text: Go in
+        next: preface.1:end
+  - id: preface.1:end
+    thread_type: affirmative
+    text: The doors close behind you.
+    next: 0
+`
+
+// orphanScene can't be reached from the start
+const orphanScene = `
+  - id: preface.2:orphan
+    thread_type: affirmative
+    text: Nobody comes here.
+    next: 0
+`
+
+// brokenScene points at a scene that doesn't exist
+const brokenScene = `
+  - id: preface.2:broken
+    thread_type: affirmative
+    text: A door to nowhere.
+    next: preface.9:missing
+`
+
+// writeScenesDir writes a scenes directory holding one chapter file
+func writeScenesDir(t *testing.T, scenes string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "preface.yaml"), []byte(scenes), 0o644); err != nil {
+		t.Fatalf("Failed to write scenes: %v", err)
+	}
+	return dir
+}
+
+func TestRun_ExitCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		scenes string
+		args   []string
+		want   int
+	}{
+		{"clean", cleanScenes, nil, 0},
+		{"unreachable in strict mode", cleanScenes + orphanScene, nil, 1},
+		{"unreachable in warn mode", cleanScenes + orphanScene, []string{"-mode", "warn"}, 0},
+		{"broken link in warn mode", cleanScenes + brokenScene, []string{"-mode", "warn"}, 1},
+		{"bad format", cleanScenes, []string{"-format", "xml"}, 2},
+		{"bad mode", cleanScenes, []string{"-mode", "loose"}, 2},
+		{"unknown flag", cleanScenes, []string{"-verbose"}, 2},
+		{"graph", cleanScenes, []string{"graph"}, 0},
+		{"graph bad format", cleanScenes, []string{"graph", "-format", "svg"}, 2},
+		{"graph with errors", cleanScenes + brokenScene, []string{"graph"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append(slices.Clone(tt.args), writeScenesDir(t, tt.scenes))
+			var stdout, stderr bytes.Buffer
+			if got := run(args, &stdout, &stderr); got != tt.want {
+				t.Errorf("run(%v) = %d, want %d\nstdout: %s\nstderr: %s", tt.args, got, tt.want, &stdout, &stderr)
+			}
+		})
+	}
+}
+
+func TestRun_TooManyDirs(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if got := run([]string{t.TempDir(), t.TempDir()}, &stdout, &stderr); got != 2 {
+		t.Errorf("run with two directories = %d, want 2", got)
+	}
+	if !strings.Contains(stderr.String(), "usage: storylint") {
+		t.Errorf("stderr = %q, want the usage", &stderr)
+	}
+}
+
+func TestRun_JSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         string
+		wantOK       bool
+		wantSeverity story.Severity
+	}{
+		{"strict", "strict", false, story.SeverityError},
+		{"warn", "warn", true, story.SeverityWarning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeScenesDir(t, cleanScenes+orphanScene)
+			var stdout, stderr bytes.Buffer
+			run([]string{"-format", "json", "-mode", tt.mode, dir}, &stdout, &stderr)
+
+			var report Report
+			if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+				t.Fatalf("output is not a JSON report: %v\n%s", err, &stdout)
+			}
+			if report.OK != tt.wantOK {
+				t.Errorf("ok = %v, want %v", report.OK, tt.wantOK)
+			}
+			if len(report.Diagnostics) != 1 {
+				t.Fatalf("diagnostics = %+v, want one", report.Diagnostics)
+			}
+			d := report.Diagnostics[0]
+			if d.Severity != tt.wantSeverity || d.SceneID != "preface.2:orphan" || d.Kind != story.IssueUnreachable || d.Message == "" {
+				t.Errorf("diagnostic = %+v, want %s unreachable on preface.2:orphan", d, tt.wantSeverity)
+			}
+			if report.Errors+report.Warnings != 1 {
+				t.Errorf("errors = %d, warnings = %d, want one finding", report.Errors, report.Warnings)
+			}
+			if (report.Stats != nil) != tt.wantOK {
+				t.Errorf("stats = %+v, want them only on a valid graph", report.Stats)
+			}
+		})
+	}
+
+	// The keys are the contract with editors and CI scripts
+	dir := writeScenesDir(t, cleanScenes+orphanScene)
+	var stdout, stderr bytes.Buffer
+	run([]string{"-format", "json", dir}, &stdout, &stderr)
+	var raw struct {
+		Diagnostics []map[string]any `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &raw); err != nil || len(raw.Diagnostics) != 1 {
+		t.Fatalf("diagnostics = %s, err %v", &stdout, err)
+	}
+	for _, key := range []string{"severity", "file", "scene", "kind", "message"} {
+		if _, ok := raw.Diagnostics[0][key]; !ok {
+			t.Errorf("diagnostic %v has no %q key", raw.Diagnostics[0], key)
+		}
+	}
+}
+
+func TestRun_Text(t *testing.T) {
+	dir := writeScenesDir(t, cleanScenes+orphanScene)
+	var stdout, stderr bytes.Buffer
+	if got := run([]string{"-mode", "warn", dir}, &stdout, &stderr); got != 0 {
+		t.Fatalf("run = %d, want 0\n%s", got, &stderr)
+	}
+
+	out := stdout.String()
+	for _, want := range []string{
+		"warning: ",
+		"unreachable from start scene preface.0:start",
+		"scene graph is valid (1 warning(s))",
+		"Scenes:         3",
+		"By thread type: affirmative=2, multi=1",
+		"preface",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+
+	stdout.Reset()
+	run([]string{"-stats=false", dir}, &stdout, &stderr)
+	if out := stdout.String(); !strings.Contains(out, "1 error(s), 0 warning(s)") || strings.Contains(out, "Scenes:") {
+		t.Errorf("strict output without stats:\n%s", out)
+	}
+}
+
+func TestRun_Graph(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"dot", []string{"digraph story {", `"preface.0:start" -> "preface.1:end"`}},
+		{"mermaid", []string{"flowchart", "preface.0:start", "preface.1:end"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if got := run([]string{"graph", "-format", tt.format, writeScenesDir(t, cleanScenes)}, &stdout, &stderr); got != 0 {
+				t.Fatalf("run = %d, want 0\n%s", got, &stderr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("output does not contain %q:\n%s", want, &stdout)
+				}
+			}
+		})
+	}
+}
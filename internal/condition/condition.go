@@ -0,0 +1,546 @@
+package condition
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jredh-dev/divine-academy/internal/impact"
+)
+
+// Env supplies the player state a condition is evaluated against
+type Env interface {
+	Attribute(path string) int                // Attribute value, e.g. player.intelligence
+	HasVisited(sceneID string) bool           // Whether the scene has been visited
+	Knows(concept string) bool                // Whether the concept has been learned
+	HasChosen(sceneID string, index int) bool // Whether the choice was taken on that scene
+	KarmaScore() int                          // Hidden karma; positive leans empathic, negative coercive
+	HasAbility(id string) bool                // Whether the ability has been unlocked
+}
+
+// Type is the static type of an expression
+type Type int
+
+const (
+	TypeBool Type = iota
+	TypeInt
+)
+
+func (t Type) String() string {
+	if t == TypeInt {
+		return "number"
+	}
+	return "boolean"
+}
+
+// Ref is a scene (and optionally a choice) referenced by a condition
+type Ref struct {
+	SceneID string
+	Choice  int // -1 when only the scene is referenced
+}
+
+// Expr is a parsed, type-checked condition
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse parses and type-checks a condition
+// The expression must evaluate to a boolean
+func Parse(src string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected '%s' at position %d", tok.text, tok.pos)
+	}
+	if root.typ() != TypeBool {
+		return nil, fmt.Errorf("condition must be true/false, got a %s (did you forget a comparison?)", root.typ())
+	}
+
+	return &Expr{src: strings.TrimSpace(src), root: root}, nil
+}
+
+// MustParse is like Parse but panics on error (for tests and static data)
+func MustParse(src string) *Expr {
+	e, err := Parse(src)
+	if err != nil {
+		panic(fmt.Sprintf("condition %q: %v", src, err))
+	}
+	return e
+}
+
+// Eval evaluates the condition against a player environment
+// A nil expression is always true
+func (e *Expr) Eval(env Env) bool {
+	if e == nil {
+		return true
+	}
+	return e.root.evalBool(env)
+}
+
+// String returns the source text of the condition
+func (e *Expr) String() string {
+	if e == nil {
+		return ""
+	}
+	return e.src
+}
+
+// Refs returns every scene referenced by visited() or chose()
+func (e *Expr) Refs() []Ref {
+	if e == nil {
+		return nil
+	}
+	var refs []Ref
+	walk(e.root, func(n node) {
+		if c, ok := n.(*callNode); ok {
+			if ref, ok := c.ref(); ok {
+				refs = append(refs, ref)
+			}
+		}
+	})
+	return refs
+}
+
+// Concepts returns every concept referenced by knows()
+func (e *Expr) Concepts() []string {
+	if e == nil {
+		return nil
+	}
+	var concepts []string
+	walk(e.root, func(n node) {
+		if c, ok := n.(*callNode); ok && c.name == "knows" {
+			concepts = append(concepts, c.args[0])
+		}
+	})
+	return concepts
+}
+
+// Abilities returns every ability referenced by has_ability()
+func (e *Expr) Abilities() []string {
+	if e == nil {
+		return nil
+	}
+	var abilities []string
+	walk(e.root, func(n node) {
+		if c, ok := n.(*callNode); ok && c.name == "has_ability" {
+			abilities = append(abilities, c.args[0])
+		}
+	})
+	return abilities
+}
+
+// node is an element of the expression tree
+type node interface {
+	typ() Type
+	evalBool(env Env) bool
+	evalInt(env Env) int
+	children() []node
+}
+
+// walk visits every node in the tree depth-first
+func walk(n node, fn func(node)) {
+	fn(n)
+	for _, c := range n.children() {
+		walk(c, fn)
+	}
+}
+
+type intNode struct{ value int }
+
+func (n *intNode) typ() Type         { return TypeInt }
+func (n *intNode) evalBool(Env) bool { return n.value != 0 }
+func (n *intNode) evalInt(Env) int   { return n.value }
+func (n *intNode) children() []node  { return nil }
+
+type boolNode struct{ value bool }
+
+func (n *boolNode) typ() Type         { return TypeBool }
+func (n *boolNode) evalBool(Env) bool { return n.value }
+func (n *boolNode) evalInt(Env) int   { return 0 }
+func (n *boolNode) children() []node  { return nil }
+
+type attrNode struct{ path string }
+
+func (n *attrNode) typ() Type             { return TypeInt }
+func (n *attrNode) evalBool(env Env) bool { return env.Attribute(n.path) != 0 }
+func (n *attrNode) evalInt(env Env) int   { return env.Attribute(n.path) }
+func (n *attrNode) children() []node      { return nil }
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n *compareNode) typ() Type        { return TypeBool }
+func (n *compareNode) evalInt(Env) int  { return 0 }
+func (n *compareNode) children() []node { return []node{n.left, n.right} }
+
+func (n *compareNode) evalBool(env Env) bool {
+	l, r := n.left.evalInt(env), n.right.evalInt(env)
+	switch n.op {
+	case ">=":
+		return l >= r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	case "<":
+		return l < r
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	}
+	return false
+}
+
+type logicalNode struct {
+	and         bool
+	left, right node
+}
+
+func (n *logicalNode) typ() Type        { return TypeBool }
+func (n *logicalNode) evalInt(Env) int  { return 0 }
+func (n *logicalNode) children() []node { return []node{n.left, n.right} }
+
+func (n *logicalNode) evalBool(env Env) bool {
+	if n.and {
+		return n.left.evalBool(env) && n.right.evalBool(env)
+	}
+	return n.left.evalBool(env) || n.right.evalBool(env)
+}
+
+type notNode struct{ operand node }
+
+func (n *notNode) typ() Type             { return TypeBool }
+func (n *notNode) evalBool(env Env) bool { return !n.operand.evalBool(env) }
+func (n *notNode) evalInt(Env) int       { return 0 }
+func (n *notNode) children() []node      { return []node{n.operand} }
+
+// argKind describes what a function argument must look like
+type argKind int
+
+const (
+	argSceneID   argKind = iota // A scene ID such as preface.2:campus-tour
+	argName                     // A bare name such as division
+	argInt                      // An integer literal
+	argAlignment                // A karma alignment: empathic, neutral or coercive
+)
+
+// alignments maps each karma alignment to the scores it covers
+var alignments = map[string]func(score int) bool{
+	"empathic": func(score int) bool { return score > 0 },
+	"neutral":  func(score int) bool { return score == 0 },
+	"coercive": func(score int) bool { return score < 0 },
+}
+
+// funcSpec describes a built-in condition function
+type funcSpec struct {
+	args []argKind
+	eval func(env Env, args []string) bool
+}
+
+// funcs lists the functions available in conditions
+var funcs = map[string]funcSpec{
+	"visited": {
+		args: []argKind{argSceneID},
+		eval: func(env Env, args []string) bool { return env.HasVisited(args[0]) },
+	},
+	"knows": {
+		args: []argKind{argName},
+		eval: func(env Env, args []string) bool { return env.Knows(args[0]) },
+	},
+	"chose": {
+		args: []argKind{argSceneID, argInt},
+		eval: func(env Env, args []string) bool {
+			idx, _ := strconv.Atoi(args[1])
+			return env.HasChosen(args[0], idx)
+		},
+	},
+	"has_ability": {
+		args: []argKind{argName},
+		eval: func(env Env, args []string) bool { return env.HasAbility(args[0]) },
+	},
+	"karma": {
+		args: []argKind{argAlignment},
+		eval: func(env Env, args []string) bool { return alignments[args[0]](env.KarmaScore()) },
+	},
+	"karma_at_least": {
+		args: []argKind{argInt},
+		eval: func(env Env, args []string) bool {
+			n, _ := strconv.Atoi(args[0])
+			return env.KarmaScore() >= n
+		},
+	},
+	"karma_at_most": {
+		args: []argKind{argInt},
+		eval: func(env Env, args []string) bool {
+			n, _ := strconv.Atoi(args[0])
+			return env.KarmaScore() <= n
+		},
+	},
+}
+
+type callNode struct {
+	name string
+	spec funcSpec
+	args []string
+}
+
+func (n *callNode) typ() Type             { return TypeBool }
+func (n *callNode) evalBool(env Env) bool { return n.spec.eval(env, n.args) }
+func (n *callNode) evalInt(Env) int       { return 0 }
+func (n *callNode) children() []node      { return nil }
+
+// ref returns the scene referenced by this call, if any
+func (n *callNode) ref() (Ref, bool) {
+	if len(n.spec.args) == 0 || n.spec.args[0] != argSceneID {
+		return Ref{}, false
+	}
+	ref := Ref{SceneID: n.args[0], Choice: -1}
+	if n.name == "chose" {
+		ref.Choice, _ = strconv.Atoi(n.args[1])
+	}
+	return ref, true
+}
+
+// parser is a recursive descent parser over lexed tokens
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	tok := p.next()
+	if tok.kind != kind {
+		return tok, fmt.Errorf("expected %s at position %d, got %s", what, tok.pos, describe(tok))
+	}
+	return tok, nil
+}
+
+// parseOr: and ( "or" and )*
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		op := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if err := requireBool(op, left, right); err != nil {
+			return nil, err
+		}
+		left = &logicalNode{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd: unary ( "and" unary )*
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokAnd {
+		op := p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if err := requireBool(op, left, right); err != nil {
+			return nil, err
+		}
+		left = &logicalNode{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseUnary: "not" unary | comparison
+func (p *parser) parseUnary() (node, error) {
+	if p.peek().kind == tokNot {
+		op := p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if err := requireBool(op, operand); err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison: primary ( op primary )?
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokCompare {
+		return left, nil
+	}
+
+	op := p.next()
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if left.typ() != TypeInt || right.typ() != TypeInt {
+		return nil, fmt.Errorf("'%s' at position %d compares numbers, not %s and %s", op.text, op.pos, left.typ(), right.typ())
+	}
+	return &compareNode{op: op.text, left: left, right: right}, nil
+}
+
+// parsePrimary: "(" or ")" | call | path | int | true | false
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+
+	case tokInt:
+		v, err := strconv.Atoi(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s' at position %d", tok.text, tok.pos)
+		}
+		return &intNode{value: v}, nil
+
+	case tokTrue:
+		return &boolNode{value: true}, nil
+
+	case tokFalse:
+		return &boolNode{value: false}, nil
+
+	case tokIdent:
+		if p.peek().kind == tokLParen {
+			return p.parseCall(tok)
+		}
+		if err := impact.ValidatePath(tok.text); err != nil {
+			return nil, fmt.Errorf("at position %d: %w", tok.pos, err)
+		}
+		return &attrNode{path: tok.text}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected %s at position %d", describe(tok), tok.pos)
+}
+
+// parseCall parses the argument list of a built-in function
+func (p *parser) parseCall(name token) (node, error) {
+	spec, ok := funcs[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function '%s' at position %d (available: %s)", name.text, name.pos, funcNames())
+	}
+	p.next() // consume '('
+
+	var args []string
+	for p.peek().kind != tokRParen && p.peek().kind != tokEOF {
+		if len(args) > 0 {
+			if _, err := p.expect(tokComma, "','"); err != nil {
+				return nil, err
+			}
+		}
+		arg := p.next()
+		if arg.kind != tokIdent && arg.kind != tokInt {
+			return nil, fmt.Errorf("invalid argument %s to %s() at position %d", describe(arg), name.text, arg.pos)
+		}
+		args = append(args, arg.text)
+	}
+	if _, err := p.expect(tokRParen, "')'"); err != nil {
+		return nil, err
+	}
+
+	if len(args) != len(spec.args) {
+		return nil, fmt.Errorf("%s() takes %d argument(s), got %d", name.text, len(spec.args), len(args))
+	}
+	for i, kind := range spec.args {
+		if err := checkArg(kind, args[i]); err != nil {
+			return nil, fmt.Errorf("%s() argument %d: %w", name.text, i+1, err)
+		}
+	}
+
+	return &callNode{name: name.text, spec: spec, args: args}, nil
+}
+
+// checkArg validates a single function argument against its kind
+func checkArg(kind argKind, arg string) error {
+	switch kind {
+	case argInt:
+		if _, err := strconv.Atoi(arg); err != nil {
+			return fmt.Errorf("'%s' must be a number", arg)
+		}
+	case argSceneID:
+		if !strings.Contains(arg, ":") {
+			return fmt.Errorf("'%s' must be a scene ID (e.g., preface.2:campus-tour)", arg)
+		}
+	case argName:
+		if strings.ContainsAny(arg, ".:") {
+			return fmt.Errorf("'%s' must be a simple name", arg)
+		}
+	case argAlignment:
+		if _, ok := alignments[arg]; !ok {
+			return fmt.Errorf("'%s' must be empathic, neutral, or coercive", arg)
+		}
+	}
+	return nil
+}
+
+// requireBool checks that every operand of a logical operator is boolean
+func requireBool(op token, operands ...node) error {
+	for _, n := range operands {
+		if n.typ() != TypeBool {
+			return fmt.Errorf("'%s' at position %d needs true/false operands, got a %s", op.text, op.pos, n.typ())
+		}
+	}
+	return nil
+}
+
+// describe names a token for error messages
+func describe(tok token) string {
+	if tok.kind == tokEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("'%s'", tok.text)
+}
+
+// funcNames lists the available functions for error messages
+func funcNames() string {
+	names := make([]string, 0, len(funcs))
+	for name := range funcs {
+		names = append(names, name+"()")
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,161 @@
+package condition
+
+import (
+	"testing"
+)
+
+// testEnv is a fixed player state for evaluating conditions
+type testEnv struct {
+	attrs   map[string]int
+	visited map[string]bool
+	known   map[string]bool
+	chosen  map[string]int
+	karma   int
+	ability map[string]bool
+}
+
+func (e testEnv) Attribute(path string) int      { return e.attrs[path] }
+func (e testEnv) HasVisited(sceneID string) bool { return e.visited[sceneID] }
+func (e testEnv) Knows(concept string) bool      { return e.known[concept] }
+func (e testEnv) KarmaScore() int                { return e.karma }
+func (e testEnv) HasAbility(id string) bool      { return e.ability[id] }
+
+func (e testEnv) HasChosen(sceneID string, index int) bool {
+	idx, ok := e.chosen[sceneID]
+	return ok && idx == index
+}
+
+func newTestEnv() testEnv {
+	return testEnv{
+		attrs:   map[string]int{"player.intelligence": 3, "npc.teacher.trust": -2},
+		visited: map[string]bool{"preface.2:campus-tour": true},
+		known:   map[string]bool{"division": true},
+		chosen:  map[string]int{"preface.0:dream-start": 1},
+		karma:   2,
+		ability: map[string]bool{"division": true},
+	}
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"player.intelligence >= 3", true},
+		{"player.intelligence > 3", false},
+		{"player.intelligence==3", true},
+		{"player.strength < 1", true},
+		{"npc.teacher.trust >= -2", true},
+		{"npc.teacher.trust != -2", false},
+		{"visited(preface.2:campus-tour)", true},
+		{"visited(preface.3:teacher-choice)", false},
+		{"knows(division)", true},
+		{"knows(algebra)", false},
+		{"chose(preface.0:dream-start, 1)", true},
+		{"chose(preface.0:dream-start, 0)", false},
+		{"not knows(algebra)", true},
+		{"!knows(division)", false},
+		{"knows(division) and player.intelligence >= 3", true},
+		{"knows(algebra) && player.intelligence >= 3", false},
+		{"knows(algebra) or visited(preface.2:campus-tour)", true},
+		{"knows(algebra) || false", false},
+		{"not (knows(algebra) or knows(division))", false},
+		{"true", true},
+		{"3 > player.intelligence", false},
+		{"has_ability(division)", true},
+		{"has_ability(keep-your-cool)", false},
+		{"has_ability(division) and karma(empathic)", true},
+		{"karma(empathic)", true},
+		{"karma(coercive)", false},
+		{"not karma(neutral)", true},
+		{"karma_at_least(2)", true},
+		{"karma_at_least(3)", false},
+		{"karma_at_most(-1)", false},
+		{"karma_at_most(2) and player.intelligence >= 3", true},
+	}
+
+	env := newTestEnv()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if got := e.Eval(env); got != tt.want {
+				t.Errorf("Eval = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"player.intelligence",          // not a boolean
+		"player.intelligence = 3",      // single '='
+		"knows(division) >= 3",         // comparing a boolean
+		"player.intelligence and true", // logical on a number
+		"unknown(x)",                   // unknown function
+		"knows()",                      // wrong arity
+		"chose(preface.0:dream-start)", // wrong arity
+		"chose(preface.0:dream-start, first)",
+		"visited(campus)",        // not a scene ID
+		"knows(preface.0:dream)", // not a simple name
+		"monster.hp > 3",         // unknown entity
+		"(knows(division)",       // unbalanced parens
+		"knows(division) knows(algebra)",
+		"has_ability(mental.division)", // not a simple name
+		"karma(good)",                  // not an alignment
+		"karma_at_least(x)",            // not a number
+		"player.intelligence >= 3 & true",
+		"player.intelligence >= $",
+		"player.iq-test >= 3",        // '-' outside a scene ID
+		"player.intelligence:x >= 3", // ':' outside a scene ID
+		"player.Intelligence >= 3",   // not lowercase
+		"player.level2 >= 3",         // digits in a path
+		"npc.teacher.trust-1 >= 3",   // subtraction is not supported
+		"visited(preface.0:start) and player.a-b > 1",
+	}
+
+	for _, src := range tests {
+		t.Run(src, func(t *testing.T) {
+			if _, err := Parse(src); err == nil {
+				t.Errorf("Parse(%q) expected error", src)
+			}
+		})
+	}
+}
+
+func TestRefs(t *testing.T) {
+	e := MustParse("visited(preface.2:campus-tour) and (chose(preface.0:dream-start, 2) or knows(division))")
+	refs := e.Refs()
+
+	if len(refs) != 2 {
+		t.Fatalf("Refs() = %v, want 2 refs", refs)
+	}
+	if refs[0] != (Ref{SceneID: "preface.2:campus-tour", Choice: -1}) {
+		t.Errorf("refs[0] = %+v", refs[0])
+	}
+	if refs[1] != (Ref{SceneID: "preface.0:dream-start", Choice: 2}) {
+		t.Errorf("refs[1] = %+v", refs[1])
+	}
+
+	if got := MustParse("has_ability(division) or not has_ability(charm)").Abilities(); len(got) != 2 || got[0] != "division" || got[1] != "charm" {
+		t.Errorf("Abilities() = %v, want [division charm]", got)
+	}
+
+	concepts := e.Concepts()
+	if len(concepts) != 1 || concepts[0] != "division" {
+		t.Errorf("Concepts() = %v, want [division]", concepts)
+	}
+}
+
+func TestNilExpr(t *testing.T) {
+	var e *Expr
+	if !e.Eval(newTestEnv()) {
+		t.Error("nil expression should evaluate to true")
+	}
+	if e.String() != "" {
+		t.Errorf("nil String() = %q", e.String())
+	}
+}
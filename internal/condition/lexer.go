@@ -0,0 +1,132 @@
+package condition
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// tokenKind identifies a lexical token in a condition expression
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokInt
+	tokCompare // >=, <=, >, <, ==, !=
+	tokAnd     // and, &&
+	tokOr      // or, ||
+	tokNot     // not, !
+	tokLParen
+	tokRParen
+	tokComma
+	tokTrue
+	tokFalse
+)
+
+// token is a single lexeme with its rune index in the source
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits a condition expression into tokens
+func lex(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+	i := 0
+	args := false // Inside a function's argument list, where scene IDs appear
+
+	for i < len(runes) {
+		r := runes[i]
+		start := i
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+
+		case r == '(':
+			args = len(tokens) > 0 && tokens[len(tokens)-1].kind == tokIdent
+			tokens = append(tokens, token{tokLParen, "(", start})
+			i++
+
+		case r == ')':
+			args = false
+			tokens = append(tokens, token{tokRParen, ")", start})
+			i++
+
+		case r == ',':
+			tokens = append(tokens, token{tokComma, ",", start})
+			i++
+
+		case r == '&' || r == '|':
+			if i+1 >= len(runes) || runes[i+1] != r {
+				return nil, fmt.Errorf("unexpected '%c' at position %d (did you mean '%c%c'?)", r, start, r, r)
+			}
+			kind := tokAnd
+			if r == '|' {
+				kind = tokOr
+			}
+			tokens = append(tokens, token{kind, string(runes[i : i+2]), start})
+			i += 2
+
+		case r == '>' || r == '<' || r == '=' || r == '!':
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, token{tokCompare, string(runes[i : i+2]), start})
+				i += 2
+				continue
+			}
+			switch r {
+			case '>', '<':
+				tokens = append(tokens, token{tokCompare, string(r), start})
+			case '!':
+				tokens = append(tokens, token{tokNot, "!", start})
+			default:
+				return nil, fmt.Errorf("unexpected '=' at position %d (use '==' to compare)", start)
+			}
+			i++
+
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			i++
+			for i < len(runes) && unicode.IsDigit(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{tokInt, string(runes[start:i]), start})
+
+		case unicode.IsLetter(r) || r == '_':
+			// Identifiers cover attribute paths and, as arguments, scene IDs such
+			// as preface.2:campus-tour
+			for i < len(runes) && isIdentRune(runes[i], args) {
+				i++
+			}
+			text := string(runes[start:i])
+			kind := tokIdent
+			switch text {
+			case "and":
+				kind = tokAnd
+			case "or":
+				kind = tokOr
+			case "not":
+				kind = tokNot
+			case "true":
+				kind = tokTrue
+			case "false":
+				kind = tokFalse
+			}
+			tokens = append(tokens, token{kind, text, start})
+
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' at position %d", r, start)
+		}
+	}
+
+	tokens = append(tokens, token{tokEOF, "", len(runes)})
+	return tokens, nil
+}
+
+// isIdentRune reports whether r may appear inside an identifier
+// '-' and ':' only belong to scene IDs, so they are accepted in arguments alone.
+func isIdentRune(r rune, args bool) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || args && (r == ':' || r == '-')
+}
@@ -0,0 +1,632 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrBadExpression is wrapped by ParseExpression errors
+var ErrBadExpression = errors.New("not an expression")
+
+// Limits on what ParseExpression will read, so hostile input can't exhaust
+// the stack
+const (
+	MaxExpressionLength = 500 // Runes
+	MaxExpressionDepth  = 50  // Nested parentheses, signs, and exponents
+)
+
+// Expression is a parsed arithmetic or algebraic expression such as "2(x+3)"
+type Expression struct {
+	Source    string
+	root      exprNode
+	variables []string // Sorted names of the variables used
+}
+
+// exprNode is one node of an expression tree
+type exprNode interface {
+	eval(vars map[string]float64) float64
+	shape(b *strings.Builder)        // Writes the structure with every number as #
+	numbers(dst []float64) []float64 // Appends the numbers left out of shape, in order
+}
+
+type (
+	numberNode   float64
+	constantNode string // Named constant such as pi
+	variableNode string
+	negateNode   struct{ x exprNode }
+	binaryNode   struct {
+		op   byte // One of + - * / ^
+		l, r exprNode
+	}
+	callNode struct {
+		fn  string
+		arg exprNode
+	}
+)
+
+// exprConstants are names that stand for a number rather than a variable
+var exprConstants = map[string]float64{"pi": math.Pi, "π": math.Pi}
+
+// exprFuncs are the functions an expression may call, always with parentheses
+var exprFuncs = map[string]func(float64) float64{
+	"sqrt": math.Sqrt,
+	"abs":  math.Abs,
+	"exp":  math.Exp,
+	"ln":   math.Log,
+	"log":  math.Log10,
+	"sin":  math.Sin,
+	"cos":  math.Cos,
+	"tan":  math.Tan,
+}
+
+func (n numberNode) eval(map[string]float64) float64 { return float64(n) }
+func (n numberNode) shape(b *strings.Builder)        { b.WriteByte('#') }
+func (n numberNode) numbers(dst []float64) []float64 { return append(dst, float64(n)) }
+
+func (n constantNode) eval(map[string]float64) float64 { return exprConstants[string(n)] }
+func (n constantNode) shape(b *strings.Builder)        { b.WriteByte('#') }
+func (n constantNode) numbers(dst []float64) []float64 { return append(dst, exprConstants[string(n)]) }
+
+func (n variableNode) eval(vars map[string]float64) float64 { return vars[string(n)] }
+func (n variableNode) shape(b *strings.Builder)             { b.WriteString(string(n)) }
+func (n variableNode) numbers(dst []float64) []float64      { return dst }
+
+func (n negateNode) eval(vars map[string]float64) float64 { return -n.x.eval(vars) }
+func (n negateNode) shape(b *strings.Builder) {
+	b.WriteString("(neg ")
+	n.x.shape(b)
+	b.WriteByte(')')
+}
+func (n negateNode) numbers(dst []float64) []float64 { return n.x.numbers(dst) }
+
+func (n binaryNode) eval(vars map[string]float64) float64 {
+	l, r := n.l.eval(vars), n.r.eval(vars)
+	switch n.op {
+	case '+':
+		return l + r
+	case '-':
+		return l - r
+	case '*':
+		return l * r
+	case '/':
+		return l / r
+	default:
+		return math.Pow(l, r)
+	}
+}
+
+func (n binaryNode) shape(b *strings.Builder) {
+	b.WriteByte('(')
+	b.WriteByte(n.op)
+	b.WriteByte(' ')
+	n.l.shape(b)
+	b.WriteByte(' ')
+	n.r.shape(b)
+	b.WriteByte(')')
+}
+func (n binaryNode) numbers(dst []float64) []float64 { return n.r.numbers(n.l.numbers(dst)) }
+
+func (n callNode) eval(vars map[string]float64) float64 { return exprFuncs[n.fn](n.arg.eval(vars)) }
+func (n callNode) shape(b *strings.Builder) {
+	b.WriteString("(" + n.fn + " ")
+	n.arg.shape(b)
+	b.WriteByte(')')
+}
+func (n callNode) numbers(dst []float64) []float64 { return n.arg.numbers(dst) }
+
+// ParseExpression reads an expression the way a student might write it:
+// numbers, single-letter variables, + - * / ^ (or ** and ×, ÷, −), parentheses,
+// implicit multiplication (2x, 3(x+1), xy), the constant pi, and the functions
+// sqrt, abs, exp, ln, log, sin, cos, and tan. A leading "y =" is ignored.
+func ParseExpression(input string) (*Expression, error) {
+	if utf8.RuneCountInString(input) > MaxExpressionLength {
+		return nil, fmt.Errorf("%w: longer than %d characters", ErrBadExpression, MaxExpressionLength)
+	}
+	s := strings.TrimSpace(input)
+	s = strings.TrimRight(s, ".")
+	s = assignmentPrefix.ReplaceAllString(s, "")
+
+	tokens, err := tokenizeExpression(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: empty", ErrBadExpression)
+	}
+
+	p := &exprParser{tokens: tokens, variables: map[string]bool{}}
+	root, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.unexpected()
+	}
+
+	variables := make([]string, 0, len(p.variables))
+	for name := range p.variables {
+		variables = append(variables, name)
+	}
+	sort.Strings(variables)
+
+	return &Expression{Source: input, root: root, variables: variables}, nil
+}
+
+// Variables returns the sorted names of the variables in the expression
+func (e *Expression) Variables() []string {
+	return e.variables
+}
+
+// Eval evaluates the expression; variables missing from vars are zero
+// Undefined results, such as division by zero, are NaN or ±Inf
+func (e *Expression) Eval(vars map[string]float64) float64 {
+	return e.root.eval(vars)
+}
+
+// shape describes the expression's structure with its numbers left out
+func (e *Expression) shape() string {
+	var b strings.Builder
+	e.root.shape(&b)
+	return b.String()
+}
+
+// numbers returns the numbers left out of shape, in order
+func (e *Expression) numbers() []float64 {
+	return e.root.numbers(nil)
+}
+
+// exprTokenKind classifies an expression token
+type exprTokenKind int
+
+const (
+	tokNumber exprTokenKind = iota
+	tokName                 // Variable, constant, or function
+	tokOp                   // + - * / ^ ( )
+)
+
+// exprToken is one lexical token of an expression
+type exprToken struct {
+	kind  exprTokenKind
+	text  string
+	value float64 // For numbers
+}
+
+// tokenizeExpression splits an expression into numbers, names, and operators
+// Runs of letters that are not a function or constant name are split into
+// single-letter variables, so "xy" is x times y
+func tokenizeExpression(s string) ([]exprToken, error) {
+	var tokens []exprToken
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: bad number %q", ErrBadExpression, text)
+			}
+			tokens = append(tokens, exprToken{kind: tokNumber, text: text, value: value})
+
+		case unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && unicode.IsLetter(runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			if _, ok := exprFuncs[strings.ToLower(word)]; ok {
+				tokens = append(tokens, exprToken{kind: tokName, text: strings.ToLower(word)})
+				continue
+			}
+			if _, ok := exprConstants[strings.ToLower(word)]; ok {
+				tokens = append(tokens, exprToken{kind: tokName, text: strings.ToLower(word)})
+				continue
+			}
+			for _, letter := range word {
+				tokens = append(tokens, exprToken{kind: tokName, text: string(letter)})
+			}
+
+		default:
+			op, width := exprOperator(runes[i:])
+			if op == 0 {
+				return nil, fmt.Errorf("%w: unexpected %q", ErrBadExpression, string(r))
+			}
+			tokens = append(tokens, exprToken{kind: tokOp, text: string(op)})
+			i += width
+		}
+	}
+	return tokens, nil
+}
+
+// exprOperator reads the operator at the start of runes, returning its ASCII
+// form and how many runes it used, or 0 if there is none
+func exprOperator(runes []rune) (byte, int) {
+	switch runes[0] {
+	case '+', '-', '/', '^', '(', ')':
+		return byte(runes[0]), 1
+	case '*':
+		if len(runes) > 1 && runes[1] == '*' {
+			return '^', 2
+		}
+		return '*', 1
+	case '[':
+		return '(', 1
+	case ']':
+		return ')', 1
+	case '−':
+		return '-', 1
+	case '×', '·':
+		return '*', 1
+	case '÷':
+		return '/', 1
+	}
+	return 0, 0
+}
+
+// exprParser is a recursive-descent parser over expression tokens
+//
+//	sum     = product { ("+" | "-") product }
+//	product = unary { ("*" | "/") unary | implicit-factor }
+//	unary   = ("-" | "+") unary | power
+//	power   = primary [ "^" unary ]
+//	primary = number | name | function "(" sum ")" | "(" sum ")"
+type exprParser struct {
+	tokens    []exprToken
+	pos       int
+	depth     int // Current unary() recursion depth
+	variables map[string]bool
+}
+
+// peek returns the next token without consuming it
+func (p *exprParser) peek() (exprToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return exprToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// acceptOp consumes the next token if it is one of the operators in ops
+func (p *exprParser) acceptOp(ops string) (byte, bool) {
+	t, ok := p.peek()
+	if !ok || t.kind != tokOp || !strings.Contains(ops, t.text) {
+		return 0, false
+	}
+	p.pos++
+	return t.text[0], true
+}
+
+// unexpected describes the token at the current position as an error
+func (p *exprParser) unexpected() error {
+	t, ok := p.peek()
+	if !ok {
+		return fmt.Errorf("%w: ends too soon", ErrBadExpression)
+	}
+	return fmt.Errorf("%w: unexpected %q", ErrBadExpression, t.text)
+}
+
+func (p *exprParser) sum() (exprNode, error) {
+	left, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp("+-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, l: left, r: right}
+	}
+}
+
+func (p *exprParser) product() (exprNode, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOp("*/")
+		if !ok {
+			// Implicit multiplication: 2x, 3(x+1), (x+1)(x-1)
+			if !p.startsFactor() {
+				return left, nil
+			}
+			op = '*'
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, l: left, r: right}
+	}
+}
+
+// startsFactor reports whether the next token can begin an implicit factor
+func (p *exprParser) startsFactor() bool {
+	t, ok := p.peek()
+	return ok && (t.kind != tokOp || t.text == "(")
+}
+
+// unary is on every recursive path through the grammar, so it enforces
+// MaxExpressionDepth
+func (p *exprParser) unary() (exprNode, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > MaxExpressionDepth {
+		return nil, fmt.Errorf("%w: nested more than %d deep", ErrBadExpression, MaxExpressionDepth)
+	}
+
+	if op, ok := p.acceptOp("+-"); ok {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if op == '-' {
+			return negateNode{x}, nil
+		}
+		return x, nil
+	}
+	return p.power()
+}
+
+func (p *exprParser) power() (exprNode, error) {
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.acceptOp("^"); !ok {
+		return base, nil
+	}
+	// Right-associative, and the exponent may be negative: x^-1
+	exponent, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return binaryNode{op: '^', l: base, r: exponent}, nil
+}
+
+func (p *exprParser) primary() (exprNode, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, p.unexpected()
+	}
+
+	switch {
+	case t.kind == tokNumber:
+		p.pos++
+		return numberNode(t.value), nil
+
+	case t.kind == tokName:
+		p.pos++
+		if _, ok := exprConstants[t.text]; ok {
+			return constantNode(t.text), nil
+		}
+		if _, ok := exprFuncs[t.text]; ok {
+			if _, ok := p.acceptOp("("); !ok {
+				return nil, fmt.Errorf("%w: %s needs parentheses, as in %s(x)", ErrBadExpression, t.text, t.text)
+			}
+			arg, err := p.closeParen()
+			if err != nil {
+				return nil, err
+			}
+			return callNode{fn: t.text, arg: arg}, nil
+		}
+		p.variables[t.text] = true
+		return variableNode(t.text), nil
+
+	case t.text == "(":
+		p.pos++
+		return p.closeParen()
+	}
+	return nil, p.unexpected()
+}
+
+// closeParen parses the inside of a parenthesis whose "(" was just consumed
+func (p *exprParser) closeParen() (exprNode, error) {
+	inner, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.acceptOp(")"); !ok {
+		return nil, fmt.Errorf("%w: missing closing parenthesis", ErrBadExpression)
+	}
+	return inner, nil
+}
+
+// Defaults for ExpressionValidator
+const (
+	DefaultExpressionSamples   = 20
+	DefaultExpressionTolerance = 1e-6
+	DefaultSlipScore           = 0.5
+)
+
+// sampleRange bounds the random values tried for each variable
+const sampleRange = 10.0
+
+// ExpressionValidator accepts any expression equivalent to Expected, such as
+// "2x+6" for "2(x+3)"
+// Equivalence is checked by evaluating both at random points, so it is
+// probabilistic in principle but reliable for the expressions used in lessons.
+type ExpressionValidator struct {
+	Expected  *Expression
+	Samples   int     // Points compared (default DefaultExpressionSamples)
+	Tolerance float64 // Relative error allowed at each point (default DefaultExpressionTolerance)
+	SlipScore float64 // Credit for an arithmetic slip; 0 gives none (NewExpressionValidator sets DefaultSlipScore)
+	Seed      uint64  // Seeds the sample points, so results are repeatable
+}
+
+// NewExpressionValidator parses the expected answer
+// It fails if the answer cannot be evaluated at any sample point
+func NewExpressionValidator(expected string) (*ExpressionValidator, error) {
+	e, err := ParseExpression(expected)
+	if err != nil {
+		return nil, err
+	}
+	v := &ExpressionValidator{Expected: e, SlipScore: DefaultSlipScore}
+	for _, point := range v.points(e.variables) {
+		if finite(e.Eval(point)) {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("%q is undefined at every sample point", expected)
+}
+
+// Validate implements Validator for expression answers
+func (v *ExpressionValidator) Validate(input string) ValidationResult {
+	result := ValidationResult{AnnotatedInput: template.HTML(template.HTMLEscapeString(input))}
+
+	got, err := ParseExpression(input)
+	if err != nil {
+		result.Rejection = RejectBadExpression
+		return result
+	}
+
+	if v.equivalent(got) {
+		result.Correct = true
+		result.Score = 1.0
+		return result
+	}
+
+	if v.slip(got) {
+		result.Score = v.SlipScore
+		result.Rejection = RejectArithmeticSlip
+		return result
+	}
+
+	result.Rejection = RejectNotEquivalent
+	return result
+}
+
+// samples pairs the expected and given values at each point where the
+// expected expression is defined
+func (v *ExpressionValidator) samples(got *Expression) (expected, given []float64) {
+	for _, point := range v.points(mergeVariables(v.Expected.variables, got.variables)) {
+		want := v.Expected.Eval(point)
+		if !finite(want) {
+			continue
+		}
+		expected = append(expected, want)
+		given = append(given, got.Eval(point))
+	}
+	return expected, given
+}
+
+// equivalent reports whether got agrees with the expected answer at every point
+func (v *ExpressionValidator) equivalent(got *Expression) bool {
+	expected, given := v.samples(got)
+	if len(expected) == 0 {
+		return false
+	}
+	for i := range expected {
+		if !v.close(given[i], expected[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// slip reports whether got looks like the expected answer with one
+// arithmetic mistake: the same form with a single number wrong, as in
+// "(x+2)(x+4)" for "(x+2)(x+3)", or a miscalculated constant term, as in
+// "2x+5" for "2(x+3)". Leaving out a term or scaling the whole answer, as in
+// "2x" or "-2x-6", is not a slip. Answers without variables are never slips.
+func (v *ExpressionValidator) slip(got *Expression) bool {
+	if len(v.Expected.variables) == 0 {
+		return false
+	}
+	if got.shape() == v.Expected.shape() {
+		wrong := 0
+		want := v.Expected.numbers()
+		for i, n := range got.numbers() {
+			if !closeTo(n, want[i], DefaultExpressionTolerance) {
+				wrong++
+			}
+		}
+		return wrong == 1
+	}
+
+	// Off by the same amount everywhere, with a constant term of its own
+	expected, given := v.samples(got)
+	if len(expected) < 2 {
+		return false
+	}
+	offset := given[0] - expected[0]
+	if !finite(offset) {
+		return false
+	}
+	for i := range expected {
+		if !v.close(given[i]-expected[i], offset) {
+			return false
+		}
+	}
+	constant := got.Eval(nil) // Every variable zero
+	return finite(constant) && !closeTo(constant, 0, DefaultExpressionTolerance)
+}
+
+// points returns the sample points for a set of variables
+// The same validator always returns the same points
+func (v *ExpressionValidator) points(variables []string) []map[string]float64 {
+	n := v.Samples
+	if n <= 0 {
+		n = DefaultExpressionSamples
+	}
+	rng := rand.New(rand.NewPCG(v.Seed, 0x5eed))
+	points := make([]map[string]float64, n)
+	for i := range points {
+		point := make(map[string]float64, len(variables))
+		for _, name := range variables {
+			point[name] = (rng.Float64()*2 - 1) * sampleRange
+		}
+		points[i] = point
+	}
+	return points
+}
+
+// close compares two sample values within the relative tolerance
+func (v *ExpressionValidator) close(got, want float64) bool {
+	if !finite(got) {
+		return false
+	}
+	tolerance := v.Tolerance
+	if tolerance <= 0 {
+		tolerance = DefaultExpressionTolerance
+	}
+	return closeTo(got, want, tolerance*math.Max(1, math.Abs(want)))
+}
+
+// mergeVariables returns the sorted union of two sorted name lists
+func mergeVariables(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var merged []string
+	for _, name := range append(append([]string{}, a...), b...) {
+		if !seen[name] {
+			seen[name] = true
+			merged = append(merged, name)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// finite reports whether f is neither NaN nor infinite
+func finite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
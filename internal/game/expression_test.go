@@ -0,0 +1,179 @@
+package game
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		input string
+		vars  map[string]float64
+		want  float64
+	}{
+		{"2 + 3 * 4", nil, 14},
+		{"(2 + 3) * 4", nil, 20},
+		{"2^3^2", nil, 512},
+		{"2**3", nil, 8},
+		{"-x^2", map[string]float64{"x": 3}, -9},
+		{"x^-1", map[string]float64{"x": 4}, 0.25},
+		{"2x", map[string]float64{"x": 5}, 10},
+		{"2(x+3)", map[string]float64{"x": 1}, 8},
+		{"(x+1)(x-1)", map[string]float64{"x": 3}, 8},
+		{"3xy", map[string]float64{"x": 2, "y": 5}, 30},
+		{"x2", map[string]float64{"x": 4}, 8},
+		{"10 - 4 - 3", nil, 3},
+		{"12 / 3 / 2", nil, 2},
+		{"2 × 3 ÷ 4 − 1", nil, 0.5},
+		{"[x+1]*2", map[string]float64{"x": 1}, 4},
+		{"sqrt(16) + abs(-2)", nil, 6},
+		{"2pi", nil, 2 * math.Pi},
+		{"y = 2x + 1", map[string]float64{"x": 3}, 7},
+		{"x + 1.", map[string]float64{"x": 1}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			e, err := ParseExpression(tt.input)
+			if err != nil {
+				t.Fatalf("ParseExpression(%q) error: %v", tt.input, err)
+			}
+			if got := e.Eval(tt.vars); !closeTo(got, tt.want, 0) {
+				t.Errorf("Eval = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExpression_Variables(t *testing.T) {
+	e, err := ParseExpression("3yx + sqrt(z) - pi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := e.Variables(), []string{"x", "y", "z"}; !slices.Equal(got, want) {
+		t.Errorf("Variables() = %v, want %v", got, want)
+	}
+}
+
+func TestParseExpression_Errors(t *testing.T) {
+	for _, input := range []string{"", "2 +", "(x + 1", "x + 1)", "2 $ 3", "sqrt 4", "1.2.3", "*2"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseExpression(input)
+			if !errors.Is(err, ErrBadExpression) {
+				t.Errorf("ParseExpression(%q) error = %v, want ErrBadExpression", input, err)
+			}
+		})
+	}
+}
+
+func TestParseExpression_Limits(t *testing.T) {
+	nested := func(n int) string {
+		return strings.Repeat("(", n) + "x" + strings.Repeat(")", n)
+	}
+
+	if _, err := ParseExpression(nested(MaxExpressionDepth - 1)); err != nil {
+		t.Errorf("ParseExpression(%d nested parentheses) error: %v", MaxExpressionDepth-1, err)
+	}
+
+	for name, input := range map[string]string{
+		"too deep":       nested(MaxExpressionDepth + 1),
+		"too many signs": strings.Repeat("-", MaxExpressionDepth+1) + "x",
+		"too long":       strings.Repeat("x+", MaxExpressionLength) + "x",
+		"huge":           strings.Repeat("(", 2<<20),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseExpression(input); !errors.Is(err, ErrBadExpression) {
+				t.Errorf("error = %v, want ErrBadExpression", err)
+			}
+		})
+	}
+
+	v, err := NewExpressionValidator("x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Validate(nested(100000)).Rejection; got != RejectBadExpression {
+		t.Errorf("Validate(deeply nested) Rejection = %q, want %q", got, RejectBadExpression)
+	}
+}
+
+func TestExpressionValidator(t *testing.T) {
+	tests := []struct {
+		expected, input string
+		wantCorrect     bool
+		wantScore       float64
+		wantRejection   RejectionReason
+	}{
+		{"2(x+3)", "2x+6", true, 1, ""},
+		{"2(x+3)", "6 + 2*x", true, 1, ""},
+		{"(x+1)^2", "x^2 + 2x + 1", true, 1, ""},
+		{"x^2 - 1", "(x-1)(x+1)", true, 1, ""},
+		{"(x^2-1)/(x-1)", "x+1", true, 1, ""},
+		{"3/4", "0.75", true, 1, ""},
+		{"a^2 + b^2", "b^2 + a^2", true, 1, ""},
+
+		// Arithmetic slips earn partial credit
+		{"2(x+3)", "2x+5", false, DefaultSlipScore, RejectArithmeticSlip},
+		{"2(x+3)", "2x+3", false, DefaultSlipScore, RejectArithmeticSlip},
+		{"2(x+3)", "2(x+4)", false, DefaultSlipScore, RejectArithmeticSlip},
+		{"(x+2)(x+3)", "(x+2)(x+4)", false, DefaultSlipScore, RejectArithmeticSlip},
+		{"3x^2", "3x^3", false, DefaultSlipScore, RejectArithmeticSlip},
+
+		// Dropped terms, scaled answers, and several wrong numbers are not slips
+		{"2(x+3)", "2x", false, 0, RejectNotEquivalent},
+		{"2(x+3)", "x+3", false, 0, RejectNotEquivalent},
+		{"2(x+3)", "-2x-6", false, 0, RejectNotEquivalent},
+		{"2(x+3)", "5(x+9)", false, 0, RejectNotEquivalent},
+
+		// Different expressions, and wrong numbers for constant answers, do not
+		{"2(x+3)", "x^2 + 6", false, 0, RejectNotEquivalent},
+		{"2(x+3)", "2y+6", false, 0, RejectNotEquivalent},
+		{"2(x+3)", "0", false, 0, RejectNotEquivalent},
+		{"3/4", "4/5", false, 0, RejectNotEquivalent},
+		{"2(x+3)", "2(x+3", false, 0, RejectBadExpression},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected+" vs "+tt.input, func(t *testing.T) {
+			v, err := NewExpressionValidator(tt.expected)
+			if err != nil {
+				t.Fatalf("NewExpressionValidator(%q) error: %v", tt.expected, err)
+			}
+			result := v.Validate(tt.input)
+			if result.Correct != tt.wantCorrect {
+				t.Errorf("Correct = %v, want %v", result.Correct, tt.wantCorrect)
+			}
+			if result.Score != tt.wantScore {
+				t.Errorf("Score = %v, want %v", result.Score, tt.wantScore)
+			}
+			if result.Rejection != tt.wantRejection {
+				t.Errorf("Rejection = %q, want %q", result.Rejection, tt.wantRejection)
+			}
+		})
+	}
+}
+
+func TestExpressionValidator_NoSlipScore(t *testing.T) {
+	v, err := NewExpressionValidator("2(x+3)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.SlipScore = 0
+
+	result := v.Validate("2x+5")
+	if result.Score != 0 || result.Rejection != RejectArithmeticSlip {
+		t.Errorf("Validate = score %v, rejection %q; want no credit for the slip", result.Score, result.Rejection)
+	}
+}
+
+func TestNewExpressionValidator_Errors(t *testing.T) {
+	if _, err := NewExpressionValidator("2(x+"); !errors.Is(err, ErrBadExpression) {
+		t.Errorf("unparseable answer: error = %v, want ErrBadExpression", err)
+	}
+	if _, err := NewExpressionValidator("sqrt(-1 - x^2)"); err == nil {
+		t.Error("answer undefined everywhere: expected an error")
+	}
+}
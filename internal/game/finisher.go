@@ -0,0 +1,127 @@
+package game
+
+import (
+	"html/template"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenStatus describes how one typed word compares to the target passage
+type TokenStatus string
+
+const (
+	TokenCorrect TokenStatus = "correct" // Matches the target word
+	TokenPartial TokenStatus = "partial" // Still being typed and a prefix of the target word
+	TokenWrong   TokenStatus = "wrong"   // Does not match the target word
+)
+
+// Token is one word of the player's input with its status
+type Token struct {
+	Text   string      `json:"text"`
+	Status TokenStatus `json:"status"`
+}
+
+// Completion is the progress of a partially typed passage
+// It never includes target words the player has not typed yet
+type Completion struct {
+	Tokens    []Token `json:"tokens"`
+	Correct   int     `json:"correct"`   // Typed words that match the target
+	Total     int     `json:"total"`     // Words in the target passage
+	Remaining int     `json:"remaining"` // Target words not yet typed
+	Complete  bool    `json:"complete"`  // Every word typed and correct
+}
+
+// FinisherValidator checks a passage typed word by word against a target
+// Case and punctuation are ignored so players can focus on the words
+type FinisherValidator struct {
+	Target string
+	words  []string // Comparison keys for each target word
+}
+
+// NewFinisherValidator creates a validator for the target passage
+func NewFinisherValidator(target string) *FinisherValidator {
+	fields := strings.Fields(target)
+	words := make([]string, len(fields))
+	for i, f := range fields {
+		words[i] = tokenKey(f)
+	}
+	return &FinisherValidator{Target: target, words: words}
+}
+
+// Check tokenizes partial input and reports per-token correctness
+// The last word counts as still being typed unless the input ends in whitespace
+func (v *FinisherValidator) Check(input string) Completion {
+	fields := strings.Fields(input)
+	typing := len(fields) > 0 && !endsInSpace(input)
+
+	c := Completion{Tokens: make([]Token, len(fields)), Total: len(v.words)}
+	for i, field := range fields {
+		status := TokenWrong
+		if i < len(v.words) {
+			key := tokenKey(field)
+			switch {
+			case key == v.words[i]:
+				status = TokenCorrect
+				c.Correct++
+			case typing && i == len(fields)-1 && strings.HasPrefix(v.words[i], key):
+				status = TokenPartial
+			}
+		}
+		c.Tokens[i] = Token{Text: field, Status: status}
+	}
+
+	if len(fields) < len(v.words) {
+		c.Remaining = len(v.words) - len(fields)
+	}
+	c.Complete = c.Correct == len(v.words) && len(fields) == len(v.words)
+	return c
+}
+
+// Validate implements Validator; the passage is correct once it is complete
+func (v *FinisherValidator) Validate(input string) ValidationResult {
+	c := v.Check(input)
+
+	score := 0.0
+	if c.Total > 0 {
+		score = float64(c.Correct) / float64(c.Total)
+	}
+
+	return ValidationResult{
+		Correct:        c.Complete,
+		Score:          score,
+		AnnotatedInput: c.Annotate(),
+	}
+}
+
+// Annotate renders the typed words with their status as HTML
+func (c Completion) Annotate() template.HTML {
+	var b strings.Builder
+	for i, t := range c.Tokens {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(`<span class="token token-`)
+		b.WriteString(string(t.Status))
+		b.WriteString(`">`)
+		b.WriteString(template.HTMLEscapeString(t.Text))
+		b.WriteString(`</span>`)
+	}
+	return template.HTML(b.String())
+}
+
+// tokenKey reduces a word to the letters and digits used for comparison
+func tokenKey(word string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, word)
+}
+
+// endsInSpace reports whether the input's last character is whitespace
+func endsInSpace(s string) bool {
+	r, size := utf8.DecodeLastRuneInString(s)
+	return size > 0 && unicode.IsSpace(r)
+}
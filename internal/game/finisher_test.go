@@ -0,0 +1,121 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFinisherValidator_Check(t *testing.T) {
+	v := NewFinisherValidator("The Great War began in 1914.")
+
+	tests := []struct {
+		name          string
+		input         string
+		wantStatuses  []TokenStatus
+		wantCorrect   int
+		wantRemaining int
+		wantComplete  bool
+	}{
+		{
+			name:          "empty input",
+			input:         "",
+			wantStatuses:  []TokenStatus{},
+			wantRemaining: 6,
+		},
+		{
+			name:          "typing first word",
+			input:         "Th",
+			wantStatuses:  []TokenStatus{TokenPartial},
+			wantRemaining: 5,
+		},
+		{
+			name:          "finished word awaits next",
+			input:         "the ",
+			wantStatuses:  []TokenStatus{TokenCorrect},
+			wantCorrect:   1,
+			wantRemaining: 5,
+		},
+		{
+			name:          "wrong word",
+			input:         "The Grate ",
+			wantStatuses:  []TokenStatus{TokenCorrect, TokenWrong},
+			wantCorrect:   1,
+			wantRemaining: 4,
+		},
+		{
+			name:          "wrong prefix while typing",
+			input:         "The Gx",
+			wantStatuses:  []TokenStatus{TokenCorrect, TokenWrong},
+			wantCorrect:   1,
+			wantRemaining: 4,
+		},
+		{
+			name:         "complete ignoring case and punctuation",
+			input:        "the great war, began in 1914",
+			wantStatuses: []TokenStatus{TokenCorrect, TokenCorrect, TokenCorrect, TokenCorrect, TokenCorrect, TokenCorrect},
+			wantCorrect:  6,
+			wantComplete: true,
+		},
+		{
+			name:         "extra words are wrong",
+			input:        "The Great War began in 1914 exactly",
+			wantStatuses: []TokenStatus{TokenCorrect, TokenCorrect, TokenCorrect, TokenCorrect, TokenCorrect, TokenCorrect, TokenWrong},
+			wantCorrect:  6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := v.Check(tt.input)
+
+			if len(c.Tokens) != len(tt.wantStatuses) {
+				t.Fatalf("Tokens = %v, want %d tokens", c.Tokens, len(tt.wantStatuses))
+			}
+			for i, want := range tt.wantStatuses {
+				if c.Tokens[i].Status != want {
+					t.Errorf("Tokens[%d].Status = %v, want %v", i, c.Tokens[i].Status, want)
+				}
+			}
+			if c.Correct != tt.wantCorrect {
+				t.Errorf("Correct = %d, want %d", c.Correct, tt.wantCorrect)
+			}
+			if c.Remaining != tt.wantRemaining {
+				t.Errorf("Remaining = %d, want %d", c.Remaining, tt.wantRemaining)
+			}
+			if c.Complete != tt.wantComplete {
+				t.Errorf("Complete = %v, want %v", c.Complete, tt.wantComplete)
+			}
+			if c.Total != 6 {
+				t.Errorf("Total = %d, want 6", c.Total)
+			}
+		})
+	}
+}
+
+func TestFinisherValidator_Validate(t *testing.T) {
+	v := NewFinisherValidator("knowledge is power")
+
+	result := v.Validate("knowledge is <b>")
+	if result.Correct {
+		t.Error("Expected incomplete passage to be rejected")
+	}
+	if abs(result.Score-0.666) > 0.01 {
+		t.Errorf("Score = %.3f, want 0.666", result.Score)
+	}
+
+	annotated := string(result.AnnotatedInput)
+	if strings.Contains(annotated, "<b>") {
+		t.Errorf("Expected input to be escaped, got: %s", annotated)
+	}
+	if !strings.Contains(annotated, `<span class="token token-correct">knowledge</span>`) {
+		t.Errorf("Expected correct token markup, got: %s", annotated)
+	}
+	// The target word must not leak into feedback
+	if strings.Contains(annotated, "power") {
+		t.Errorf("Annotation revealed the target: %s", annotated)
+	}
+
+	if !v.Validate("Knowledge is power!").Correct {
+		t.Error("Expected completed passage to be accepted")
+	}
+}
@@ -0,0 +1,77 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Approach is how the player goes about a problem
+// Every approach is worth the same experience; only karma differs.
+type Approach string
+
+const (
+	ApproachNone      Approach = ""
+	ApproachEmpathize Approach = "empathize" // Understand the other side (+karma)
+	ApproachRelate    Approach = "relate"    // Prove yourself on society's terms (no karma)
+	ApproachCoerce    Approach = "coerce"    // Manipulate or seduce (-karma)
+)
+
+const (
+	ApproachXP    = 5         // Experience every approach earns, whichever is taken
+	ApproachTrack = Emotional // Approaches are ways of dealing with people
+)
+
+// ParseApproach reads an approach tag; "seduce" is accepted as coerce
+func ParseApproach(s string) (Approach, error) {
+	switch a := Approach(strings.ToLower(strings.TrimSpace(s))); a {
+	case ApproachEmpathize, ApproachRelate, ApproachCoerce:
+		return a, nil
+	case "seduce":
+		return ApproachCoerce, nil
+	}
+	return ApproachNone, fmt.Errorf("unknown approach '%s' (must be empathize, relate, or coerce)", s)
+}
+
+// Rewards returns the experience for taking this approach, the same for
+// every approach
+func (a Approach) Rewards() Rewards {
+	if a == ApproachNone {
+		return nil
+	}
+	return Rewards{ApproachTrack: ApproachXP}
+}
+
+// Karma returns the karma change for taking this approach
+func (a Approach) Karma() int {
+	switch a {
+	case ApproachEmpathize:
+		return 1
+	case ApproachCoerce:
+		return -1
+	}
+	return 0
+}
+
+// Karma is the player's hidden moral standing
+// It is never shown, but conditions can branch on it.
+type Karma struct {
+	Score     int `json:"score"`
+	Empathize int `json:"empathize,omitempty"` // Times each approach was taken
+	Relate    int `json:"relate,omitempty"`
+	Coerce    int `json:"coerce,omitempty"`
+}
+
+// Record adds an approach to the tally
+func (k *Karma) Record(a Approach) {
+	switch a {
+	case ApproachEmpathize:
+		k.Empathize++
+	case ApproachRelate:
+		k.Relate++
+	case ApproachCoerce:
+		k.Coerce++
+	default:
+		return
+	}
+	k.Score += a.Karma()
+}
@@ -0,0 +1,67 @@
+package game
+
+import "testing"
+
+func TestParseApproach(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Approach
+	}{
+		{"empathize", ApproachEmpathize},
+		{"Relate", ApproachRelate},
+		{" coerce ", ApproachCoerce},
+		{"seduce", ApproachCoerce},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseApproach(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseApproach(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "bribe", "empathise"} {
+		if _, err := ParseApproach(input); err == nil {
+			t.Errorf("ParseApproach(%q): expected error", input)
+		}
+	}
+}
+
+func TestKarma_Record(t *testing.T) {
+	var k Karma
+	for _, a := range []Approach{ApproachEmpathize, ApproachEmpathize, ApproachRelate, ApproachCoerce, ApproachNone} {
+		k.Record(a)
+	}
+	want := Karma{Score: 1, Empathize: 2, Relate: 1, Coerce: 1}
+	if k != want {
+		t.Errorf("Karma = %+v, want %+v", k, want)
+	}
+}
+
+func TestGameState_TakeApproach(t *testing.T) {
+	// Every approach is worth the same experience; only karma differs
+	for _, tt := range []struct {
+		approach Approach
+		karma    int
+	}{
+		{ApproachEmpathize, 1},
+		{ApproachRelate, 0},
+		{ApproachCoerce, -1},
+	} {
+		s := NewGameState("test.0:start")
+		s.TakeApproach(tt.approach)
+		if s.KarmaScore() != tt.karma {
+			t.Errorf("%s: karma = %d, want %d", tt.approach, s.KarmaScore(), tt.karma)
+		}
+		s.GrantRewards("test.0:start", tt.approach.Rewards())
+		if got := s.Progress.XP[ApproachTrack]; got != ApproachXP {
+			t.Errorf("%s: %s experience = %d, want %d", tt.approach, ApproachTrack, got, ApproachXP)
+		}
+	}
+
+	s := NewGameState("test.0:start")
+	s.TakeApproach(ApproachNone)
+	if s.Karma != (Karma{}) || ApproachNone.Rewards() != nil {
+		t.Errorf("untagged choice changed state: %+v", s.Karma)
+	}
+}
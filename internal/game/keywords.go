@@ -0,0 +1,131 @@
+package game
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// KeywordGroup is one concept that can be written several ways, such as
+// "WWI", "World War One", and "the Great War"
+// Any alternative satisfies the group, which counts once toward RequiredCount
+type KeywordGroup struct {
+	Name         string // Reported in MatchedWords and MissingWords (default: first alternative)
+	Alternatives []string
+}
+
+// label names the group in validation results
+func (g KeywordGroup) label() string {
+	if g.Name != "" || len(g.Alternatives) == 0 {
+		return g.Name
+	}
+	return g.Alternatives[0]
+}
+
+// groups returns AcceptedKeywords as single-alternative groups, followed by Groups
+func (v *ResponseValidator) groups() []KeywordGroup {
+	groups := make([]KeywordGroup, 0, len(v.AcceptedKeywords)+len(v.Groups))
+	for _, kw := range v.AcceptedKeywords {
+		groups = append(groups, KeywordGroup{Alternatives: []string{kw}})
+	}
+	return append(groups, v.Groups...)
+}
+
+// inputWords splits input into words with surrounding punctuation removed
+func inputWords(input string) []string {
+	fields := strings.Fields(input)
+	words := fields[:0]
+	for _, f := range fields {
+		if w := strings.Trim(f, ".,!?;:\"'()"); w != "" {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// wordKey reduces a word to the form used for comparison
+func (v *ResponseValidator) wordKey(word string) string {
+	if !v.CaseSensitive {
+		word = strings.ToLower(word)
+	}
+	if v.Stem {
+		word = Stem(word)
+	}
+	return word
+}
+
+// findPhrase finds a keyword as a run of whole input words
+// Returns the words as the player typed them
+func (v *ResponseValidator) findPhrase(words []string, phrase string) (string, bool) {
+	keys := inputWords(phrase)
+	if len(keys) == 0 {
+		return "", false
+	}
+	for i := range keys {
+		keys[i] = v.wordKey(keys[i])
+	}
+
+	for i := 0; i+len(keys) <= len(words); i++ {
+		match := true
+		for j, key := range keys {
+			if v.wordKey(words[i+j]) != key {
+				match = false
+				break
+			}
+		}
+		if match {
+			return strings.Join(words[i:i+len(keys)], " "), true
+		}
+	}
+	return "", false
+}
+
+// stemRules are suffixes removed by Stem, checked in order
+var stemRules = []struct {
+	suffix, replacement string
+}{
+	{"ies", "y"},
+	{"ied", "y"},
+	{"ing", ""},
+	{"ed", ""},
+	{"es", ""},
+	{"ly", ""},
+	{"s", ""},
+}
+
+// minStemLength stops Stem from reducing short words such as "was" or "bed"
+const minStemLength = 3
+
+// Stem reduces an English word to a rough stem so that inflections match:
+// divide, divides, divided, and dividing all become "divid"
+// It is deliberately simple; stems are only compared, never shown
+func Stem(word string) string {
+	for _, rule := range stemRules {
+		if !strings.HasSuffix(word, rule.suffix) || strings.HasSuffix(word, "ss") {
+			continue
+		}
+		stem := strings.TrimSuffix(word, rule.suffix) + rule.replacement
+		if utf8.RuneCountInString(stem) >= minStemLength {
+			word = stem
+		}
+		break
+	}
+
+	// A silent final e disappears before -ing/-ed: divide -> divid(ing)
+	if utf8.RuneCountInString(word) > minStemLength {
+		word = strings.TrimSuffix(word, "e")
+	}
+
+	// Doubled final consonants come from inflection: stopped -> stopp -> stop
+	runes := []rune(word)
+	if n := len(runes); n > minStemLength && runes[n-1] == runes[n-2] && isDoublingConsonant(runes[n-1]) {
+		word = string(runes[:n-1])
+	}
+	return word
+}
+
+// isDoublingConsonant reports whether r is a consonant that doubles before a suffix
+// l, s, and z are left alone because words like "fall" and "pass" end that way
+func isDoublingConsonant(r rune) bool {
+	return unicode.IsLetter(r) && !strings.ContainsRune("aeiouylsz", unicode.ToLower(r))
+}
@@ -0,0 +1,134 @@
+package game
+
+import "testing"
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"divide", "divides", "divided", "dividing"}, "divid"},
+		{[]string{"stop", "stops", "stopped", "stopping"}, "stop"},
+		{[]string{"study", "studies", "studied"}, "study"},
+		{[]string{"war", "wars"}, "war"},
+		{[]string{"class", "classes"}, "class"},
+		{[]string{"fall", "falls", "falling"}, "fall"},
+		{[]string{"was"}, "was"},
+		{[]string{"need", "needed"}, "need"},
+	}
+
+	for _, tt := range tests {
+		for _, word := range tt.words {
+			if got := Stem(word); got != tt.want {
+				t.Errorf("Stem(%q) = %q, want %q", word, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestResponseValidator_Groups(t *testing.T) {
+	ww1 := KeywordGroup{Name: "WWI", Alternatives: []string{"WWI", "World War One", "the Great War"}}
+	treaty := KeywordGroup{Alternatives: []string{"Versailles", "peace treaty"}}
+
+	tests := []struct {
+		name        string
+		input       string
+		required    int
+		wantCorrect bool
+		wantMatched []string
+		wantScore   float64
+	}{
+		{
+			name:        "first alternative",
+			input:       "WWI ended in Versailles",
+			required:    2,
+			wantCorrect: true,
+			wantMatched: []string{"WWI", "Versailles"},
+			wantScore:   1.0,
+		},
+		{
+			name:        "multi-word alternative",
+			input:       "After the great war, a peace treaty was signed",
+			required:    2,
+			wantCorrect: true,
+			wantMatched: []string{"WWI", "Versailles"},
+			wantScore:   1.0,
+		},
+		{
+			name:        "alternatives of one group count once",
+			input:       "WWI, also called World War One",
+			required:    2,
+			wantCorrect: false,
+			wantMatched: []string{"WWI"},
+			wantScore:   0.5,
+		},
+		{
+			name:        "words must be adjacent",
+			input:       "the war was great",
+			required:    1,
+			wantCorrect: false,
+			wantScore:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &ResponseValidator{Groups: []KeywordGroup{ww1, treaty}, RequiredCount: tt.required}
+			result := v.Validate(tt.input)
+
+			if result.Correct != tt.wantCorrect {
+				t.Errorf("Correct = %v, want %v", result.Correct, tt.wantCorrect)
+			}
+			if len(result.MatchedWords) != len(tt.wantMatched) {
+				t.Fatalf("MatchedWords = %v, want %v", result.MatchedWords, tt.wantMatched)
+			}
+			for i, want := range tt.wantMatched {
+				if result.MatchedWords[i] != want {
+					t.Errorf("MatchedWords[%d] = %q, want %q", i, result.MatchedWords[i], want)
+				}
+			}
+			if abs(result.Score-tt.wantScore) > 0.01 {
+				t.Errorf("Score = %.3f, want %.3f", result.Score, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestResponseValidator_Stemming(t *testing.T) {
+	v := NewValidator([]string{"divide"}, 1)
+	if v.Validate("I was dividing the cake").Correct {
+		t.Error("Expected inflection to be rejected without stemming")
+	}
+
+	v.Stem = true
+	result := v.Validate("I was dividing the cake")
+	if !result.Correct {
+		t.Fatal("Expected inflection to match with stemming")
+	}
+	if !containsString(string(result.AnnotatedInput), `<mark class="match-correct">dividing</mark>`) {
+		t.Errorf("Expected typed word to be highlighted, got: %s", result.AnnotatedInput)
+	}
+}
+
+func TestResponseValidator_Forbidden(t *testing.T) {
+	v := NewValidator([]string{"war"}, 1)
+	v.Forbidden = []string{"World War Two", "WWII"}
+
+	result := v.Validate("The war we call World War Two")
+	if result.Correct {
+		t.Error("Expected forbidden keyword to invalidate the answer")
+	}
+	if result.Score != 0 {
+		t.Errorf("Score = %.3f, want 0", result.Score)
+	}
+	if len(result.ForbiddenWords) != 1 || result.ForbiddenWords[0] != "World War Two" {
+		t.Errorf("ForbiddenWords = %v, want [World War Two]", result.ForbiddenWords)
+	}
+	if !containsString(string(result.AnnotatedInput), `<mark class="match-forbidden">World War Two</mark>`) {
+		t.Errorf("Expected forbidden words to be marked, got: %s", result.AnnotatedInput)
+	}
+
+	if !v.Validate("The war to end all wars").Correct {
+		t.Error("Expected answer without forbidden keywords to be accepted")
+	}
+}
@@ -0,0 +1,205 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Quantity is a parsed numeric answer with an optional unit
+type Quantity struct {
+	Value float64
+	Unit  string // As typed, e.g. "cm"; empty if there was none
+}
+
+// ErrNotANumber is returned by ParseQuantity for input that is not one number
+// with an optional unit
+var ErrNotANumber = errors.New("not a number")
+
+var (
+	// assignmentPrefix strips "x =" or "answer:" from the front of an answer
+	assignmentPrefix = regexp.MustCompile(`^(?:[A-Za-z]\w*\s*[=:])\s*`)
+
+	// numberPattern matches, in order of preference: a mixed number (1 3/4),
+	// a fraction (3/4), or a decimal with optional thousands separators and exponent
+	numberPattern = regexp.MustCompile(`^(?:` +
+		`(?P<whole>\d+)\s+(?P<mnum>\d+)\s*/\s*(?P<mden>\d+)` +
+		`|(?P<num>\d+)\s*/\s*(?P<den>\d+)` +
+		`|(?P<dec>(?:\d{1,3}(?:,\d{3})+|\d+)(?:\.\d+)?|\.\d+)(?P<exp>[eE][+-]?\d+)?` +
+		`)`)
+
+	// unitPattern matches what may follow a number as its unit: words such
+	// as "cm", "km/h", "m^2", or "apples", but not a second number or stray
+	// punctuation, as in "1914 and 1918" or "1,5"
+	unitPattern = regexp.MustCompile(`^[\p{L}%°µ](?:[^\d\s]|\^\d+)*(?:\s+(?:[^\d\s]|\^\d+)+)*$`)
+)
+
+// ParseQuantity reads a number written the way a student might write it:
+// integers, decimals, 1,000 separators, fractions (3/4), mixed numbers (1 3/4),
+// scientific notation (1.5e3), percentages (25%), a trailing unit (12 cm),
+// and an optional leading variable (x = 7)
+func ParseQuantity(input string) (Quantity, error) {
+	s := strings.TrimSpace(input)
+	s = strings.TrimRight(s, ".!")
+	s = assignmentPrefix.ReplaceAllString(s, "")
+
+	sign := 1.0
+	for _, minus := range []string{"-", "−"} {
+		if rest, ok := strings.CutPrefix(s, minus); ok {
+			sign, s = -1, rest
+		}
+	}
+	s = strings.TrimSpace(strings.TrimPrefix(s, "+"))
+
+	m := numberPattern.FindStringSubmatch(s)
+	if m == nil {
+		return Quantity{}, ErrNotANumber
+	}
+	group := func(name string) string { return m[numberPattern.SubexpIndex(name)] }
+
+	var value float64
+	switch {
+	case group("whole") != "":
+		whole, _ := strconv.ParseFloat(group("whole"), 64)
+		frac, err := fraction(group("mnum"), group("mden"))
+		if err != nil {
+			return Quantity{}, err
+		}
+		value = whole + frac
+	case group("num") != "":
+		frac, err := fraction(group("num"), group("den"))
+		if err != nil {
+			return Quantity{}, err
+		}
+		value = frac
+	default:
+		digits := strings.ReplaceAll(group("dec"), ",", "") + group("exp")
+		v, err := strconv.ParseFloat(digits, 64)
+		if err != nil {
+			return Quantity{}, ErrNotANumber
+		}
+		value = v
+	}
+
+	unit := strings.TrimSpace(s[len(m[0]):])
+	if unit != "" && !unitPattern.MatchString(unit) {
+		return Quantity{}, fmt.Errorf("%w: %q is not a unit", ErrNotANumber, unit)
+	}
+	return Quantity{Value: sign * value, Unit: unit}, nil
+}
+
+// fraction divides two digit strings
+func fraction(num, den string) (float64, error) {
+	n, _ := strconv.ParseFloat(num, 64)
+	d, _ := strconv.ParseFloat(den, 64)
+	if d == 0 {
+		return 0, fmt.Errorf("%w: division by zero", ErrNotANumber)
+	}
+	return n / d, nil
+}
+
+// unitInfo places a unit in a dimension with its size in that dimension's base unit
+type unitInfo struct {
+	dimension string
+	factor    float64
+}
+
+// units lists the units answers may be converted between
+// Names are matched case-insensitively, and a trailing plural "s" is ignored
+// on names longer than two letters, so "ms" is not metres
+var units = map[string]unitInfo{
+	// Ratios; a bare number is a ratio of 1
+	"%": {"ratio", 0.01}, "percent": {"ratio", 0.01},
+
+	// Length (metres)
+	"mm": {"length", 0.001}, "millimeter": {"length", 0.001}, "millimetre": {"length", 0.001},
+	"cm": {"length", 0.01}, "centimeter": {"length", 0.01}, "centimetre": {"length", 0.01},
+	"m": {"length", 1}, "meter": {"length", 1}, "metre": {"length", 1},
+	"km": {"length", 1000}, "kilometer": {"length", 1000}, "kilometre": {"length", 1000},
+	"in": {"length", 0.0254}, "inch": {"length", 0.0254}, "inches": {"length", 0.0254},
+	"ft": {"length", 0.3048}, "foot": {"length", 0.3048}, "feet": {"length", 0.3048},
+	"yd": {"length", 0.9144}, "yard": {"length", 0.9144},
+	"mi": {"length", 1609.344}, "mile": {"length", 1609.344},
+
+	// Mass (grams)
+	"mg": {"mass", 0.001}, "milligram": {"mass", 0.001},
+	"g": {"mass", 1}, "gram": {"mass", 1},
+	"kg": {"mass", 1000}, "kilogram": {"mass", 1000},
+	"oz": {"mass", 28.349523125}, "ounce": {"mass", 28.349523125},
+	"lb": {"mass", 453.59237}, "pound": {"mass", 453.59237},
+
+	// Time (seconds)
+	"ms": {"time", 0.001}, "millisecond": {"time", 0.001},
+	"s": {"time", 1}, "sec": {"time", 1}, "second": {"time", 1},
+	"min": {"time", 60}, "minute": {"time", 60},
+	"h": {"time", 3600}, "hr": {"time", 3600}, "hour": {"time", 3600},
+	"day": {"time", 86400},
+
+	// Volume (litres)
+	"ml": {"volume", 0.001}, "milliliter": {"volume", 0.001}, "millilitre": {"volume", 0.001},
+	"l": {"volume", 1}, "liter": {"volume", 1}, "litre": {"volume", 1},
+}
+
+// lookupUnit finds a known unit by name
+func lookupUnit(name string) (unitInfo, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if u, ok := units[name]; ok {
+		return u, true
+	}
+	if singular := strings.TrimSuffix(name, "s"); singular != name && len(name) > 2 {
+		u, ok := units[singular]
+		return u, ok
+	}
+	return unitInfo{}, false
+}
+
+// KnownUnit reports whether a unit can be converted to and from others
+func KnownUnit(name string) bool {
+	_, ok := lookupUnit(name)
+	return ok
+}
+
+// sameUnitName compares unit names that have no conversion, e.g. "apples"
+func sameUnitName(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	return a == b || strings.TrimSuffix(a, "s") == strings.TrimSuffix(b, "s")
+}
+
+// convert expresses q in the target unit ("" for a bare number)
+// Returns false if the units measure different things
+func convert(q Quantity, target string) (float64, bool) {
+	if q.Unit == "" && target == "" {
+		return q.Value, true
+	}
+
+	from, fromKnown := lookupUnit(q.Unit)
+	to, toKnown := lookupUnit(target)
+
+	// A bare number and a percentage are both ratios
+	if q.Unit == "" {
+		from, fromKnown = unitInfo{"ratio", 1}, toKnown && to.dimension == "ratio"
+	}
+	if target == "" {
+		to, toKnown = unitInfo{"ratio", 1}, fromKnown && from.dimension == "ratio"
+	}
+
+	if fromKnown && toKnown {
+		if from.dimension != to.dimension {
+			return 0, false
+		}
+		return q.Value * from.factor / to.factor, true
+	}
+	if q.Unit != "" && target != "" && sameUnitName(q.Unit, target) {
+		return q.Value, true
+	}
+	return 0, false
+}
+
+// closeTo compares floats with a little slack for rounding in conversions
+func closeTo(a, b, tolerance float64) bool {
+	slack := 1e-9 * math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+	return math.Abs(a-b) <= tolerance+slack
+}
@@ -0,0 +1,237 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantValue float64
+		wantUnit  string
+	}{
+		{"42", 42, ""},
+		{"-3.5", -3.5, ""},
+		{"+7", 7, ""},
+		{".25", 0.25, ""},
+		{"1,000", 1000, ""},
+		{"1,234,567.5", 1234567.5, ""},
+		{"3/4", 0.75, ""},
+		{"3 / 4", 0.75, ""},
+		{"1 3/4", 1.75, ""},
+		{"-1 1/2", -1.5, ""},
+		{"1.5e3", 1500, ""},
+		{"2E-2", 0.02, ""},
+		{"25%", 25, "%"},
+		{"12 cm", 12, "cm"},
+		{"3/4 cup", 0.75, "cup"},
+		{"60 km/h", 60, "km/h"},
+		{"5 m^2", 5, "m^2"},
+		{"3 square feet", 3, "square feet"},
+		{"x = 7", 7, ""},
+		{"answer: 1914.", 1914, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			q, err := ParseQuantity(tt.input)
+			if err != nil {
+				t.Fatalf("ParseQuantity(%q) error: %v", tt.input, err)
+			}
+			if !closeTo(q.Value, tt.wantValue, 0) {
+				t.Errorf("Value = %v, want %v", q.Value, tt.wantValue)
+			}
+			if q.Unit != tt.wantUnit {
+				t.Errorf("Unit = %q, want %q", q.Unit, tt.wantUnit)
+			}
+		})
+	}
+}
+
+func TestParseQuantity_Errors(t *testing.T) {
+	for _, input := range []string{"", "not a number", "about ten", "1/0", "x =", "1914 and 1918", "1,5", "12 (cm)"} {
+		if _, err := ParseQuantity(input); !errors.Is(err, ErrNotANumber) {
+			t.Errorf("ParseQuantity(%q) error = %v, want ErrNotANumber", input, err)
+		}
+	}
+}
+
+func TestKnownUnit_Plurals(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"ms", "millisecond", true},
+		{"ms", "m", false},
+		{"hrs", "hour", true},
+		{"meters", "m", true},
+		{"kgs", "kg", true},
+	}
+	for _, tt := range tests {
+		ua, okA := lookupUnit(tt.a)
+		ub, okB := lookupUnit(tt.b)
+		if !okA || !okB {
+			t.Errorf("lookupUnit(%q), lookupUnit(%q) = %v, %v, want both known", tt.a, tt.b, okA, okB)
+			continue
+		}
+		if got := ua == ub; got != tt.same {
+			t.Errorf("%q and %q same unit = %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+}
+
+func TestNumericValidator_Rich(t *testing.T) {
+	tests := []struct {
+		name          string
+		validator     NumericValidator
+		input         string
+		wantCorrect   bool
+		wantRejection RejectionReason
+	}{
+		{
+			name:        "fraction equals decimal answer",
+			validator:   NumericValidator{AcceptedValues: []float64{0.75}},
+			input:       "3/4",
+			wantCorrect: true,
+		},
+		{
+			name:        "repeating fraction within rounding",
+			validator:   NumericValidator{AcceptedValues: []float64{1.0 / 3}},
+			input:       "1/3",
+			wantCorrect: true,
+		},
+		{
+			name:        "thousands separator",
+			validator:   NumericValidator{AcceptedValues: []float64{1000}},
+			input:       "1,000",
+			wantCorrect: true,
+		},
+		{
+			name:        "percentage of a ratio",
+			validator:   NumericValidator{AcceptedValues: []float64{0.25}},
+			input:       "25%",
+			wantCorrect: true,
+		},
+		{
+			name:        "percentage of a bare percent answer",
+			validator:   NumericValidator{AcceptedValues: []float64{25}},
+			input:       "25%",
+			wantCorrect: true,
+		},
+		{
+			name:          "percentage matching neither reading",
+			validator:     NumericValidator{AcceptedValues: []float64{25}},
+			input:         "0.25%",
+			wantRejection: RejectWrongValue,
+		},
+		{
+			name:        "percentage of a percent answer",
+			validator:   NumericValidator{AcceptedValues: []float64{25}, Unit: "%"},
+			input:       "25 percent",
+			wantCorrect: true,
+		},
+		{
+			name:        "unit converted",
+			validator:   NumericValidator{AcceptedValues: []float64{12}, Unit: "cm"},
+			input:       "120 mm",
+			wantCorrect: true,
+		},
+		{
+			name:        "plural unit name",
+			validator:   NumericValidator{AcceptedValues: []float64{2}, Unit: "km"},
+			input:       "2000 meters",
+			wantCorrect: true,
+		},
+		{
+			name:        "unit optional by default",
+			validator:   NumericValidator{AcceptedValues: []float64{12}, Unit: "cm"},
+			input:       "12",
+			wantCorrect: true,
+		},
+		{
+			name:          "required unit missing",
+			validator:     NumericValidator{AcceptedValues: []float64{12}, Unit: "cm", RequireUnit: true},
+			input:         "12",
+			wantRejection: RejectMissingUnit,
+		},
+		{
+			name:          "incompatible unit",
+			validator:     NumericValidator{AcceptedValues: []float64{12}, Unit: "cm"},
+			input:         "12 kg",
+			wantRejection: RejectWrongUnit,
+		},
+		{
+			name:          "unit on a bare answer",
+			validator:     NumericValidator{AcceptedValues: []float64{12}},
+			input:         "12 cm",
+			wantRejection: RejectWrongUnit,
+		},
+		{
+			name:        "custom unit by name",
+			validator:   NumericValidator{AcceptedValues: []float64{3}, Unit: "apples"},
+			input:       "3 apple",
+			wantCorrect: true,
+		},
+		{
+			name:        "relative tolerance",
+			validator:   NumericValidator{AcceptedValues: []float64{1000}, RelativeTolerance: 0.05},
+			input:       "1.04e3",
+			wantCorrect: true,
+		},
+		{
+			name:          "outside relative tolerance",
+			validator:     NumericValidator{AcceptedValues: []float64{1000}, RelativeTolerance: 0.05},
+			input:         "1060",
+			wantRejection: RejectWrongValue,
+		},
+		{
+			name:        "inside range",
+			validator:   NumericValidator{Ranges: []NumericRange{{Min: 10, Max: 12}}},
+			input:       "x = 11.5",
+			wantCorrect: true,
+		},
+		{
+			name:          "outside range",
+			validator:     NumericValidator{Ranges: []NumericRange{{Min: 10, Max: 12}}},
+			input:         "12 1/2",
+			wantRejection: RejectWrongValue,
+		},
+		{
+			name:          "two numbers",
+			validator:     NumericValidator{AcceptedValues: []float64{1914}},
+			input:         "1914 and 1918",
+			wantRejection: RejectUnparseable,
+		},
+		{
+			name:          "decimal comma",
+			validator:     NumericValidator{AcceptedValues: []float64{1.5}},
+			input:         "1,5",
+			wantRejection: RejectUnparseable,
+		},
+		{
+			name:        "milliseconds are not metres",
+			validator:   NumericValidator{AcceptedValues: []float64{0.5}, Unit: "s"},
+			input:       "500 ms",
+			wantCorrect: true,
+		},
+		{
+			name:          "unparseable",
+			validator:     NumericValidator{AcceptedValues: []float64{7}},
+			input:         "seven",
+			wantRejection: RejectUnparseable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.validator.Validate(tt.input)
+			if result.Correct != tt.wantCorrect {
+				t.Errorf("Correct = %v, want %v", result.Correct, tt.wantCorrect)
+			}
+			if result.Rejection != tt.wantRejection {
+				t.Errorf("Rejection = %q, want %q", result.Rejection, tt.wantRejection)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package game
+
+import "regexp"
+
+// PatternRule is a regular expression an answer must match, or must not
+// match when Forbidden is set, such as a date format or "x = 4" in any spacing
+type PatternRule struct {
+	Name      string // Describes the rule to the player, e.g. "a year like 1914"
+	Pattern   *regexp.Regexp
+	Forbidden bool
+}
+
+// PatternResult is the outcome of one pattern rule
+type PatternResult struct {
+	Name      string
+	Forbidden bool
+	Matched   bool   // Whether the pattern was found in the answer
+	Text      string // First matching text, if any
+}
+
+// Passed reports whether the rule is satisfied
+func (r PatternResult) Passed() bool {
+	return r.Matched != r.Forbidden
+}
+
+// checkPatterns runs every pattern rule against the input and records matched
+// text in typed for highlighting. It returns the per-rule results, how many
+// rules are required and how many of those matched, and whether any
+// forbidden pattern matched.
+func (v *ResponseValidator) checkPatterns(input string, typed map[string]string) (results []PatternResult, required, satisfied int, violated bool) {
+	for _, rule := range v.Patterns {
+		result := PatternResult{Name: rule.Name, Forbidden: rule.Forbidden}
+		if loc := rule.Pattern.FindStringIndex(input); loc != nil {
+			result.Matched = true
+			result.Text = input[loc[0]:loc[1]]
+		}
+		text := result.Text
+		results = append(results, result)
+
+		if rule.Forbidden {
+			if result.Matched {
+				violated = true
+				if text != "" {
+					typed[text] = "match-forbidden"
+				}
+			}
+			continue
+		}
+
+		required++
+		if result.Matched {
+			satisfied++
+			if text != "" {
+				typed[text] = "match-correct"
+			}
+		}
+	}
+	return results, required, satisfied, violated
+}
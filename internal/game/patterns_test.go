@@ -0,0 +1,113 @@
+package game
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestResponseValidator_Patterns(t *testing.T) {
+	equation := PatternRule{Name: "the equation", Pattern: regexp.MustCompile(`(?i)x\s*=\s*4\b`)}
+	date := PatternRule{Name: "a full date", Pattern: regexp.MustCompile(`\b\d{1,2} [A-Z][a-z]+ \d{4}\b`)}
+	guess := PatternRule{Name: "question marks", Pattern: regexp.MustCompile(`\?\?`), Forbidden: true}
+
+	tests := []struct {
+		name        string
+		keywords    []string
+		rules       []PatternRule
+		input       string
+		wantCorrect bool
+		wantScore   float64
+		wantPassed  []bool
+	}{
+		{
+			name:        "required pattern in any spacing",
+			rules:       []PatternRule{equation},
+			input:       "so X=4",
+			wantCorrect: true,
+			wantScore:   1.0,
+			wantPassed:  []bool{true},
+		},
+		{
+			name:        "required pattern missing",
+			rules:       []PatternRule{equation},
+			input:       "x = 5",
+			wantCorrect: false,
+			wantScore:   0,
+			wantPassed:  []bool{false},
+		},
+		{
+			name:        "keywords and patterns both needed",
+			keywords:    []string{"Sarajevo"},
+			rules:       []PatternRule{date},
+			input:       "In Sarajevo in 1914",
+			wantCorrect: false,
+			wantScore:   0.5,
+			wantPassed:  []bool{false},
+		},
+		{
+			name:        "keywords and patterns satisfied",
+			keywords:    []string{"Sarajevo"},
+			rules:       []PatternRule{date},
+			input:       "In Sarajevo on 28 June 1914",
+			wantCorrect: true,
+			wantScore:   1.0,
+			wantPassed:  []bool{true},
+		},
+		{
+			name:        "forbidden pattern",
+			rules:       []PatternRule{equation, guess},
+			input:       "x = 4 ??",
+			wantCorrect: false,
+			wantScore:   0,
+			wantPassed:  []bool{true, false},
+		},
+		{
+			name:        "forbidden pattern absent",
+			rules:       []PatternRule{guess},
+			input:       "x = 4",
+			wantCorrect: true,
+			wantScore:   1.0,
+			wantPassed:  []bool{true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewValidator(tt.keywords, len(tt.keywords))
+			v.Patterns = tt.rules
+			result := v.Validate(tt.input)
+
+			if result.Correct != tt.wantCorrect {
+				t.Errorf("Correct = %v, want %v", result.Correct, tt.wantCorrect)
+			}
+			if abs(result.Score-tt.wantScore) > 0.01 {
+				t.Errorf("Score = %.3f, want %.3f", result.Score, tt.wantScore)
+			}
+			if len(result.Patterns) != len(tt.wantPassed) {
+				t.Fatalf("Patterns = %+v, want %d results", result.Patterns, len(tt.wantPassed))
+			}
+			for i, want := range tt.wantPassed {
+				if got := result.Patterns[i].Passed(); got != want {
+					t.Errorf("Patterns[%d].Passed() = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestResponseValidator_AnnotatePatterns(t *testing.T) {
+	v := NewValidator(nil, 0)
+	v.Patterns = []PatternRule{
+		{Name: "equation", Pattern: regexp.MustCompile(`x\s*=\s*4`)},
+		{Name: "guess", Pattern: regexp.MustCompile(`\?\?`), Forbidden: true},
+	}
+	result := v.Validate("x =4 ??")
+
+	annotated := string(result.AnnotatedInput)
+	if !containsString(annotated, `<mark class="match-correct">x =4</mark>`) {
+		t.Errorf("Expected equation to be highlighted, got: %s", annotated)
+	}
+	if !containsString(annotated, `<mark class="match-forbidden">??</mark>`) {
+		t.Errorf("Expected forbidden text to be marked, got: %s", annotated)
+	}
+}
@@ -0,0 +1,172 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Tracks are the experience categories that earn ability points, in display order
+var Tracks = []KeywordCategory{Mental, Physical, Emotional}
+
+// AbilityPointXP is the experience in a track that earns one ability point in it
+const AbilityPointXP = 10
+
+var (
+	ErrNoAbilityPoints = errors.New("no ability points to spend in this track")
+	ErrAbilityOwned    = errors.New("ability already unlocked")
+)
+
+// ParseTrack reads an experience track name
+func ParseTrack(s string) (KeywordCategory, error) {
+	track := KeywordCategory(strings.ToLower(strings.TrimSpace(s)))
+	if !slices.Contains(Tracks, track) {
+		return "", fmt.Errorf("unknown track '%s' (must be mental, physical, or emotional)", s)
+	}
+	return track, nil
+}
+
+// Rewards is the experience a scene or choice grants, by track
+type Rewards map[KeywordCategory]int
+
+// Add returns the sum of two rewards
+func (r Rewards) Add(other Rewards) Rewards {
+	if len(other) == 0 {
+		return r
+	}
+	sum := make(Rewards, len(r)+len(other))
+	for track, xp := range r {
+		sum[track] += xp
+	}
+	for track, xp := range other {
+		sum[track] += xp
+	}
+	return sum
+}
+
+// Scale returns the rewards earned for an answer with the given score,
+// rounded to whole experience points
+func (r Rewards) Scale(score float64) Rewards {
+	scaled := make(Rewards, len(r))
+	for track, xp := range r {
+		if earned := int(math.Round(float64(xp) * score)); earned > 0 {
+			scaled[track] = earned
+		}
+	}
+	return scaled
+}
+
+// Ability is an unlockable ability from the catalogue
+type Ability struct {
+	ID          string
+	Name        string
+	Track       KeywordCategory // Points from this track unlock it
+	Description string
+}
+
+// Progress is the player's experience and the abilities bought with it
+type Progress struct {
+	XP        map[KeywordCategory]int `json:"xp,omitempty"`
+	Spent     map[KeywordCategory]int `json:"spent,omitempty"` // Ability points spent per track
+	Abilities []string                `json:"abilities,omitempty"`
+	Rewarded  HashSet                 `json:"rewarded_scenes,omitempty"`   // Scenes whose rewards were granted
+	Closed    HashSet                 `json:"rewarded_chapters,omitempty"` // Trimmed chapters, whose scenes reward nothing more
+}
+
+// Earned returns the ability points a track has earned in total
+func (p *Progress) Earned(track KeywordCategory) int {
+	return p.XP[track] / AbilityPointXP
+}
+
+// Points returns the unspent ability points in a track
+func (p *Progress) Points(track KeywordCategory) int {
+	return p.Earned(track) - p.Spent[track]
+}
+
+// ToNextPoint returns the experience still needed for the next point in a track
+func (p *Progress) ToNextPoint(track KeywordCategory) int {
+	return AbilityPointXP - p.XP[track]%AbilityPointXP
+}
+
+// Gain adds experience to a track and returns the ability points it earned
+func (p *Progress) Gain(track KeywordCategory, xp int) int {
+	if xp <= 0 {
+		return 0
+	}
+	if p.XP == nil {
+		p.XP = map[KeywordCategory]int{}
+	}
+	before := p.Earned(track)
+	p.XP[track] += xp
+	return p.Earned(track) - before
+}
+
+// Has reports whether an ability has been unlocked
+func (p *Progress) Has(id string) bool {
+	return slices.Contains(p.Abilities, id)
+}
+
+// Unlock spends a point from the ability's track on it
+func (p *Progress) Unlock(a Ability) error {
+	if p.Has(a.ID) {
+		return fmt.Errorf("%s: %w", a.Name, ErrAbilityOwned)
+	}
+	if p.Points(a.Track) <= 0 {
+		return fmt.Errorf("%s: %w", a.Name, ErrNoAbilityPoints)
+	}
+	if p.Spent == nil {
+		p.Spent = map[KeywordCategory]int{}
+	}
+	p.Spent[a.Track]++
+	p.Abilities = append(p.Abilities, a.ID)
+	return nil
+}
+
+// HashSet is a compact set of scene or chapter IDs, stored as 32-bit hashes
+// so it stays small in the session
+// A hash collision can only make an ID read as already in the set.
+type HashSet []byte
+
+// idHash returns the hash an ID is stored under
+func idHash(id string) [4]byte {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return [4]byte(h.Sum(nil))
+}
+
+// index returns the offset of an ID's hash in the set, or -1
+func (s HashSet) index(id string) int {
+	key := idHash(id)
+	for i := 0; i+len(key) <= len(s); i += len(key) {
+		if [4]byte(s[i:i+len(key)]) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Has reports whether an ID is in the set
+func (s HashSet) Has(id string) bool {
+	return s.index(id) >= 0
+}
+
+// Add puts an ID in the set
+// Returns false if it was already there
+func (s *HashSet) Add(id string) bool {
+	if s.Has(id) {
+		return false
+	}
+	key := idHash(id)
+	*s = append(*s, key[:]...)
+	return true
+}
+
+// Remove takes an ID out of the set
+func (s *HashSet) Remove(id string) {
+	if i := s.index(id); i >= 0 {
+		*s = slices.Delete(*s, i, i+4)
+	}
+}
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTrack(t *testing.T) {
+	for input, want := range map[string]KeywordCategory{"mental": Mental, "Physical": Physical, " emotional ": Emotional} {
+		got, err := ParseTrack(input)
+		if err != nil || got != want {
+			t.Errorf("ParseTrack(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "magic", "spiritual"} {
+		if _, err := ParseTrack(input); err == nil {
+			t.Errorf("ParseTrack(%q): expected error", input)
+		}
+	}
+}
+
+func TestRewards(t *testing.T) {
+	r := Rewards{Mental: 5}.Add(Rewards{Mental: 2, Emotional: 3})
+	if r[Mental] != 7 || r[Emotional] != 3 {
+		t.Errorf("Add = %v, want mental 7 emotional 3", r)
+	}
+
+	scaled := r.Scale(0.5)
+	if scaled[Mental] != 4 || scaled[Emotional] != 2 {
+		t.Errorf("Scale(0.5) = %v, want mental 4 emotional 2", scaled)
+	}
+	if got := r.Scale(0); len(got) != 0 {
+		t.Errorf("Scale(0) = %v, want nothing", got)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	var p Progress
+	division := Ability{ID: "division", Name: "Division", Track: Mental}
+
+	if err := p.Unlock(division); !errors.Is(err, ErrNoAbilityPoints) {
+		t.Errorf("Unlock without points: error = %v, want ErrNoAbilityPoints", err)
+	}
+
+	if got := p.Gain(Mental, 7); got != 0 {
+		t.Errorf("Gain(7) earned %d points, want 0", got)
+	}
+	if got := p.ToNextPoint(Mental); got != 3 {
+		t.Errorf("ToNextPoint = %d, want 3", got)
+	}
+	if got := p.Gain(Mental, 15); got != 2 {
+		t.Errorf("Gain(15) earned %d points, want 2", got)
+	}
+	if p.Points(Mental) != 2 || p.Points(Emotional) != 0 {
+		t.Errorf("Points = mental %d emotional %d, want 2 and 0", p.Points(Mental), p.Points(Emotional))
+	}
+
+	// Points only unlock abilities in their own track
+	if err := p.Unlock(Ability{ID: "keep-your-cool", Track: Emotional}); !errors.Is(err, ErrNoAbilityPoints) {
+		t.Errorf("Unlock emotional with mental points: error = %v", err)
+	}
+	if err := p.Unlock(division); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if !p.Has("division") || p.Points(Mental) != 1 {
+		t.Errorf("after Unlock: Has = %v, Points = %d", p.Has("division"), p.Points(Mental))
+	}
+	if err := p.Unlock(division); !errors.Is(err, ErrAbilityOwned) {
+		t.Errorf("second Unlock: error = %v, want ErrAbilityOwned", err)
+	}
+}
+
+func TestHashSet(t *testing.T) {
+	var s HashSet
+	if !s.Add("preface.0:start") || s.Add("preface.0:start") {
+		t.Error("Add should report only a new ID")
+	}
+	s.Add("preface.1:next")
+	if !s.Has("preface.0:start") || !s.Has("preface.1:next") || s.Has("preface.2:later") {
+		t.Errorf("set %x has the wrong members", s)
+	}
+	s.Remove("preface.0:start")
+	if s.Has("preface.0:start") || !s.Has("preface.1:next") || len(s) != 4 {
+		t.Errorf("after Remove, set = %x, want only preface.1:next", s)
+	}
+}
@@ -0,0 +1,312 @@
+package game
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jredh-dev/divine-academy/internal/genie"
+	"github.com/jredh-dev/divine-academy/internal/grading"
+	"github.com/jredh-dev/divine-academy/internal/impact"
+)
+
+// MaxStoredResponseLength caps how much of an open response is kept in
+// the session, since the whole state must fit inside a cookie
+const MaxStoredResponseLength = 280
+
+// GameState tracks a single player's progress through the story
+type GameState struct {
+	CurrentScene string                    `json:"current_scene"`
+	Visited      []string                  `json:"visited"`
+	Choices      []ChoiceRecord            `json:"choices"`
+	Responses    map[string]string         `json:"responses"` // Open responses keyed by scene ID
+	Attributes   impact.Store              `json:"attributes"`
+	Known        []string                  `json:"known,omitempty"`   // Concepts the player has learned
+	Answers      map[string]grading.Answer `json:"answers,omitempty"` // Graded answers keyed by scene ID
+	Grades       grading.Record            `json:"grades,omitempty"`  // Best grade ever earned per chapter
+	Played       map[string]grading.Grade  `json:"played,omitempty"`  // Chapter grades on this playthrough
+	Genie        genie.Bottle              `json:"genie,omitzero"`
+	Hints        map[string]int            `json:"hints,omitempty"` // Hint tiers revealed, keyed by scene ID
+	Karma        Karma                     `json:"karma,omitzero"`  // Hidden from the player
+	Progress     Progress                  `json:"progress,omitzero"`
+}
+
+// ChoiceRecord logs a choice the player made
+type ChoiceRecord struct {
+	SceneID string `json:"scene"`
+	Index   int    `json:"index"`
+}
+
+// NewGameState creates a state positioned at the starting scene
+func NewGameState(startSceneID string) *GameState {
+	s := &GameState{
+		Responses:  map[string]string{},
+		Attributes: impact.NewStore(),
+	}
+	s.Enter(startSceneID)
+	return s
+}
+
+// Enter moves the player to a scene and marks it as visited
+func (s *GameState) Enter(sceneID string) {
+	s.CurrentScene = sceneID
+	if !s.HasVisited(sceneID) {
+		s.Visited = append(s.Visited, sceneID)
+	}
+}
+
+// HasVisited reports whether the player has been to a scene
+func (s *GameState) HasVisited(sceneID string) bool {
+	for _, id := range s.Visited {
+		if id == sceneID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCurrent reports whether the player is currently on a scene
+func (s *GameState) IsCurrent(sceneID string) bool {
+	return s.CurrentScene == sceneID
+}
+
+// Finished reports whether the player has reached a terminal scene
+func (s *GameState) Finished() bool {
+	return s.CurrentScene == "0"
+}
+
+// ChapterOf returns the chapter part of a scene ID (preface.2:campus-tour -> preface)
+func ChapterOf(id string) string {
+	if idx := strings.Index(id, "."); idx > 0 {
+		return id[:idx]
+	}
+	return id
+}
+
+// RecordChoice logs a multiple choice selection
+// Repeating an earlier choice moves it to the end of the log rather than
+// adding it again, so replaying a loop doesn't grow the state.
+func (s *GameState) RecordChoice(sceneID string, index int) {
+	record := ChoiceRecord{SceneID: sceneID, Index: index}
+	s.Choices = append(slices.DeleteFunc(s.Choices, func(c ChoiceRecord) bool { return c == record }), record)
+}
+
+// ChoiceFor returns the most recent choice made on a scene
+func (s *GameState) ChoiceFor(sceneID string) (int, bool) {
+	for i := len(s.Choices) - 1; i >= 0; i-- {
+		if s.Choices[i].SceneID == sceneID {
+			return s.Choices[i].Index, true
+		}
+	}
+	return 0, false
+}
+
+// HasChosen reports whether the player ever picked a choice on a scene
+func (s *GameState) HasChosen(sceneID string, index int) bool {
+	for _, c := range s.Choices {
+		if c.SceneID == sceneID && c.Index == index {
+			return true
+		}
+	}
+	return false
+}
+
+// Learn marks concepts as known to the player
+func (s *GameState) Learn(concepts ...string) {
+	for _, c := range concepts {
+		if !s.Knows(c) {
+			s.Known = append(s.Known, c)
+		}
+	}
+}
+
+// Knows reports whether the player has learned a concept
+func (s *GameState) Knows(concept string) bool {
+	for _, c := range s.Known {
+		if c == concept {
+			return true
+		}
+	}
+	return false
+}
+
+// Trim forgets part of the player's history so the state fits in its cookie
+// again. The oldest open response goes first, one per call; once none are
+// left, the history of the oldest chapter the player has left, as long as
+// its grade was kept in Played or it had nothing to grade. The current
+// chapter is never trimmed, so conditions in it keep reading the same. A
+// trimmed chapter's rewards are folded into Progress.Closed, so none of its
+// scenes pay out again. Returns false if there was nothing left to forget.
+func (s *GameState) Trim() bool {
+	if id, ok := s.oldestResponse(); ok {
+		delete(s.Responses, id)
+		return true
+	}
+
+	chapter, ok := s.oldestClosedChapter()
+	if !ok {
+		return false
+	}
+	inChapter := func(id string) bool { return ChapterOf(id) == chapter }
+	for _, id := range s.Visited {
+		if inChapter(id) {
+			s.Progress.Rewarded.Remove(id)
+		}
+	}
+	s.Progress.Closed.Add(chapter)
+	s.Visited = slices.DeleteFunc(s.Visited, inChapter)
+	s.Choices = slices.DeleteFunc(s.Choices, func(c ChoiceRecord) bool { return inChapter(c.SceneID) })
+	maps.DeleteFunc(s.Answers, func(id string, _ grading.Answer) bool { return inChapter(id) })
+	maps.DeleteFunc(s.Hints, func(id string, _ int) bool { return inChapter(id) })
+	return true
+}
+
+// oldestClosedChapter returns the earliest visited chapter, other than the
+// current one, whose history can be forgotten without losing its grade
+func (s *GameState) oldestClosedChapter() (string, bool) {
+	current := ChapterOf(s.CurrentScene)
+	for _, id := range s.Visited {
+		chapter := ChapterOf(id)
+		if chapter == current {
+			continue
+		}
+		if _, graded := s.Played[chapter]; graded || !s.hasAnswers(chapter) {
+			return chapter, true
+		}
+	}
+	return "", false
+}
+
+// hasAnswers reports whether any graded answer belongs to a chapter
+func (s *GameState) hasAnswers(chapter string) bool {
+	for id := range s.Answers {
+		if ChapterOf(id) == chapter {
+			return true
+		}
+	}
+	return false
+}
+
+// oldestResponse returns the scene of the earliest stored response, in the
+// order scenes were visited
+func (s *GameState) oldestResponse() (string, bool) {
+	for id := range s.Responses {
+		if !s.HasVisited(id) {
+			return id, true
+		}
+	}
+	for _, id := range s.Visited {
+		if _, ok := s.Responses[id]; ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// Attribute returns the value of a player, npc or world attribute
+func (s *GameState) Attribute(path string) int {
+	return s.Attributes.Get(path)
+}
+
+// RecordResponse stores an open response, truncated to fit the session
+func (s *GameState) RecordResponse(sceneID, text string) {
+	if s.Responses == nil {
+		s.Responses = map[string]string{}
+	}
+	runes := []rune(text)
+	if len(runes) > MaxStoredResponseLength {
+		runes = runes[:MaxStoredResponseLength]
+	}
+	s.Responses[sceneID] = string(runes)
+}
+
+// RecordAnswer stores the answer to a graded scene, replacing any earlier one
+// Any hint taken on the scene, now or on an earlier attempt, caps the answer
+func (s *GameState) RecordAnswer(sceneID string, answer grading.Answer) {
+	if s.Answers == nil {
+		s.Answers = map[string]grading.Answer{}
+	}
+	answer.Hinted = answer.Hinted || s.Answers[sceneID].Hinted || s.Hints[sceneID] > 0
+	s.Answers[sceneID] = answer
+}
+
+// RecordAttempt stores an attempt at a graded scene that allows retries
+// Only the first attempt's score counts, so retrying until the answer is
+// accepted can't earn full credit; later attempts update the spelling.
+func (s *GameState) RecordAttempt(sceneID string, answer grading.Answer) {
+	if first, ok := s.Answers[sceneID]; ok {
+		answer.Score = first.Score
+	}
+	s.RecordAnswer(sceneID, answer)
+}
+
+// RevealHint records that the next hint tier of a scene has been shown
+// Returns the number of tiers now revealed
+func (s *GameState) RevealHint(sceneID string) int {
+	if s.Hints == nil {
+		s.Hints = map[string]int{}
+	}
+	s.Hints[sceneID]++
+	return s.Hints[sceneID]
+}
+
+// RecordGrade stores a chapter's grade on this playthrough and keeps its
+// letter if it is the best so far
+// Returns true if it is a new best
+func (s *GameState) RecordGrade(chapter string, grade grading.Grade) bool {
+	if s.Played == nil {
+		s.Played = map[string]grading.Grade{}
+	}
+	s.Played[chapter] = grade
+	if s.Grades == nil {
+		s.Grades = grading.Record{}
+	}
+	return s.Grades.Update(chapter, grade.Letter)
+}
+
+// KarmaScore returns the player's hidden karma
+func (s *GameState) KarmaScore() int {
+	return s.Karma.Score
+}
+
+// TakeApproach records the approach behind a choice in the player's karma
+// Its experience comes from Approach.Rewards, granted with the scene's.
+func (s *GameState) TakeApproach(a Approach) {
+	s.Karma.Record(a)
+}
+
+// GrantRewards adds a scene's rewards to the player's experience tracks
+// Rewards are granted once per scene, so revisiting a scene earns nothing.
+// Returns the ability points earned in each track.
+func (s *GameState) GrantRewards(sceneID string, rewards Rewards) map[KeywordCategory]int {
+	if len(rewards) == 0 || s.Progress.Closed.Has(ChapterOf(sceneID)) || !s.Progress.Rewarded.Add(sceneID) {
+		return nil
+	}
+
+	points := map[KeywordCategory]int{}
+	for _, track := range Tracks {
+		if earned := s.Progress.Gain(track, rewards[track]); earned > 0 {
+			points[track] = earned
+		}
+	}
+	return points
+}
+
+// HasAbility reports whether the player has unlocked an ability
+func (s *GameState) HasAbility(id string) bool {
+	return s.Progress.Has(id)
+}
+
+// UnlockAbility spends an ability point on an ability
+func (s *GameState) UnlockAbility(a Ability) error {
+	return s.Progress.Unlock(a)
+}
+
+// ApplyImpact applies an impact string to the player's attributes
+func (s *GameState) ApplyImpact(expr string) error {
+	if s.Attributes == nil {
+		s.Attributes = impact.NewStore()
+	}
+	_, err := s.Attributes.ApplyString(expr)
+	return err
+}
@@ -0,0 +1,288 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jredh-dev/divine-academy/internal/condition"
+	"github.com/jredh-dev/divine-academy/internal/grading"
+)
+
+func TestGameState_Enter(t *testing.T) {
+	s := NewGameState("preface.0:dream-start")
+
+	if !s.IsCurrent("preface.0:dream-start") {
+		t.Errorf("CurrentScene = %q, want start scene", s.CurrentScene)
+	}
+
+	s.Enter("preface.1:registration")
+	s.Enter("preface.0:dream-start")
+
+	if len(s.Visited) != 2 {
+		t.Errorf("Visited = %v, want 2 unique scenes", s.Visited)
+	}
+	if !s.HasVisited("preface.1:registration") {
+		t.Error("expected registration to be visited")
+	}
+	if s.HasVisited("preface.2:campus-tour") {
+		t.Error("campus tour should not be visited")
+	}
+	if s.Finished() {
+		t.Error("state should not be finished")
+	}
+
+	s.Enter("0")
+	if !s.Finished() {
+		t.Error("state should be finished after entering terminal scene")
+	}
+}
+
+func TestGameState_RecordChoice(t *testing.T) {
+	s := NewGameState("a.0:start")
+	s.RecordChoice("a.0:start", 1)
+	s.RecordChoice("a.1:next", 0)
+	s.RecordChoice("a.0:start", 2)
+
+	idx, ok := s.ChoiceFor("a.0:start")
+	if !ok || idx != 2 {
+		t.Errorf("ChoiceFor(a.0:start) = %d, %v, want 2, true", idx, ok)
+	}
+
+	if _, ok := s.ChoiceFor("a.2:never"); ok {
+		t.Error("expected no choice for unvisited scene")
+	}
+}
+
+func TestGameState_RecordResponse(t *testing.T) {
+	s := NewGameState("a.0:start")
+	s.RecordResponse("a.0:start", strings.Repeat("x", MaxStoredResponseLength+50))
+
+	if got := len(s.Responses["a.0:start"]); got != MaxStoredResponseLength {
+		t.Errorf("stored response length = %d, want %d", got, MaxStoredResponseLength)
+	}
+}
+
+func TestGameState_RecordChoice_Repeated(t *testing.T) {
+	s := NewGameState("a.0:start")
+	for range 10 {
+		s.RecordChoice("a.0:start", 0)
+		s.RecordChoice("a.0:start", 1)
+	}
+
+	if len(s.Choices) != 2 {
+		t.Errorf("Choices = %v, want each choice logged once", s.Choices)
+	}
+	if idx, _ := s.ChoiceFor("a.0:start"); idx != 1 {
+		t.Errorf("ChoiceFor(a.0:start) = %d, want 1 (latest)", idx)
+	}
+}
+
+func TestGameState_Trim_ResponsesFirst(t *testing.T) {
+	s := NewGameState("a.0:start")
+	for _, id := range []string{"a.0:start", "a.1:next", "a.2:later"} {
+		s.Enter(id)
+		s.RecordChoice(id, 0)
+	}
+	s.RecordResponse("a.2:later", "second")
+	s.RecordResponse("a.1:next", "first")
+
+	if !s.Trim() {
+		t.Fatal("Trim() = false, want a response forgotten")
+	}
+	if _, ok := s.Responses["a.1:next"]; ok {
+		t.Error("expected the oldest response to be forgotten first")
+	}
+	if _, ok := s.Responses["a.2:later"]; !ok || len(s.Visited) != 3 || len(s.Choices) != 3 {
+		t.Errorf("expected only the oldest response to go, got %+v", s)
+	}
+}
+
+func TestGameState_Trim(t *testing.T) {
+	s := NewGameState("a.0:start")
+	// a is graded, b has answers still to grade, c has nothing to grade
+	for _, id := range []string{"a.0:start", "a.1:next", "b.0:start", "c.0:start", "d.0:start", "d.1:next"} {
+		s.Enter(id)
+		s.RecordChoice(id, 0)
+		if !strings.HasPrefix(id, "c.") {
+			s.RevealHint(id)
+			s.RecordAnswer(id, grading.Answer{Score: 1})
+		}
+		s.GrantRewards(id, Rewards{Mental: 1})
+	}
+	s.RecordGrade("a", grading.Grade{Letter: grading.B, Total: 2})
+	s.Enter("d.0:start")
+
+	if !s.Trim() {
+		t.Fatal("Trim() = false, want a graded chapter forgotten")
+	}
+	if s.HasVisited("a.0:start") || s.HasChosen("a.1:next", 0) {
+		t.Error("expected the graded chapter to be forgotten first")
+	}
+	if _, ok := s.Answers["a.0:start"]; ok {
+		t.Error("expected answers in the graded chapter to be dropped")
+	}
+	if got := s.Played["a"]; got.Letter != grading.B {
+		t.Errorf("played grade for a = %+v, want B kept", got)
+	}
+	if !s.HasVisited("c.0:start") {
+		t.Error("expected one chapter forgotten per call")
+	}
+
+	for s.Trim() {
+	}
+	if s.HasVisited("c.0:start") {
+		t.Error("expected a chapter with nothing to grade to be forgotten")
+	}
+	if _, ok := s.Answers["b.0:start"]; !ok || !s.HasVisited("b.0:start") {
+		t.Error("expected a chapter with ungraded answers to be kept")
+	}
+	for _, id := range []string{"d.0:start", "d.1:next"} {
+		if _, ok := s.Answers[id]; !ok || s.Hints[id] == 0 || !s.HasVisited(id) || !s.HasChosen(id, 0) {
+			t.Errorf("expected the current chapter's %s to be kept, got %+v", id, s)
+		}
+	}
+	if !s.IsCurrent("d.0:start") {
+		t.Errorf("current scene = %s, want d.0:start", s.CurrentScene)
+	}
+
+	// Forgotten scenes stay rewarded
+	if points := s.GrantRewards("a.0:start", Rewards{Mental: 10}); len(points) != 0 || s.Progress.XP[Mental] != 6 {
+		t.Errorf("revisiting a forgotten scene earned %v (mental XP %d), want nothing", points, s.Progress.XP[Mental])
+	}
+}
+
+func TestGameState_RecordAnswer(t *testing.T) {
+	s := NewGameState("a.0:start")
+	s.RecordAnswer("a.0:start", grading.Answer{Score: 0.5, Hinted: true})
+	s.RecordAnswer("a.0:start", grading.Answer{Score: 1})
+
+	got := s.Answers["a.0:start"]
+	if got.Score != 1 {
+		t.Errorf("score = %v, want 1 (latest attempt)", got.Score)
+	}
+	if !got.Hinted {
+		t.Error("expected the earlier hint to still apply")
+	}
+}
+
+func TestGameState_RecordAttempt(t *testing.T) {
+	s := NewGameState("a.0:start")
+	s.RecordAttempt("a.0:start", grading.Answer{Score: 0.5})
+	s.RecordAttempt("a.0:start", grading.Answer{Score: 1, Spelling: 1})
+
+	got := s.Answers["a.0:start"]
+	if got.Score != 0.5 {
+		t.Errorf("score = %v, want 0.5 (first attempt)", got.Score)
+	}
+	if got.Spelling != 1 {
+		t.Errorf("spelling = %d, want 1 (latest attempt)", got.Spelling)
+	}
+}
+
+func TestGameState_RevealHint(t *testing.T) {
+	s := NewGameState("a.0:start")
+	if got := s.RevealHint("a.0:start"); got != 1 {
+		t.Errorf("RevealHint = %d, want 1", got)
+	}
+	if got := s.RevealHint("a.0:start"); got != 2 {
+		t.Errorf("RevealHint = %d, want 2", got)
+	}
+
+	s.RecordAnswer("a.0:start", grading.Answer{Score: 1})
+	if !s.Answers["a.0:start"].Hinted {
+		t.Error("expected a hinted scene's answer to be capped")
+	}
+	s.RecordAnswer("a.1:next", grading.Answer{Score: 1})
+	if s.Answers["a.1:next"].Hinted {
+		t.Error("hints on one scene should not cap another")
+	}
+}
+
+func TestGameState_RecordGrade(t *testing.T) {
+	s := NewGameState("a.0:start")
+	if !s.RecordGrade("a", grading.Grade{Letter: grading.B, Total: 1}) {
+		t.Error("first grade should be a new best")
+	}
+	if s.RecordGrade("a", grading.Grade{Letter: grading.C, Total: 1}) {
+		t.Error("C should not replace B")
+	}
+	if got := s.Grades["a"]; got != grading.B {
+		t.Errorf("best grade = %s, want B", got)
+	}
+	if got := s.Played["a"].Letter; got != grading.C {
+		t.Errorf("played grade = %s, want the latest, C", got)
+	}
+}
+
+func TestGameState_GrantRewards(t *testing.T) {
+	s := NewGameState("test.0:start")
+
+	points := s.GrantRewards("test.0:start", Rewards{Mental: 12, Emotional: 4})
+	if points[Mental] != 1 || points[Emotional] != 0 {
+		t.Errorf("GrantRewards points = %v, want 1 mental", points)
+	}
+
+	// A scene only rewards once
+	if points := s.GrantRewards("test.0:start", Rewards{Mental: 12}); len(points) != 0 {
+		t.Errorf("second GrantRewards points = %v, want none", points)
+	}
+	if s.Progress.XP[Mental] != 12 {
+		t.Errorf("mental XP = %d, want 12", s.Progress.XP[Mental])
+	}
+
+	if err := s.UnlockAbility(Ability{ID: "division", Name: "Division", Track: Mental}); err != nil {
+		t.Fatalf("UnlockAbility: %v", err)
+	}
+	if !s.HasAbility("division") {
+		t.Error("HasAbility(division) = false after unlocking")
+	}
+}
+
+func TestGameState_ApplyImpact(t *testing.T) {
+	s := NewGameState("a.0:start")
+
+	if err := s.ApplyImpact("player.strength+2"); err != nil {
+		t.Fatalf("ApplyImpact error: %v", err)
+	}
+	if err := s.ApplyImpact(""); err != nil {
+		t.Fatalf("empty impact error: %v", err)
+	}
+	if err := s.ApplyImpact("bogus"); err == nil {
+		t.Error("expected error for invalid impact")
+	}
+
+	if got := s.Attributes.Get("player.strength"); got != 2 {
+		t.Errorf("player.strength = %d, want 2", got)
+	}
+}
+
+func TestGameState_Condition(t *testing.T) {
+	s := NewGameState("a.0:start")
+	s.ApplyImpact("player.intelligence+3")
+	s.RecordChoice("a.0:start", 2)
+	s.Enter("a.1:next")
+	s.Learn("division", "division")
+
+	if len(s.Known) != 1 {
+		t.Errorf("Known = %v, want one concept", s.Known)
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"player.intelligence >= 3", true},
+		{"visited(a.1:next)", true},
+		{"visited(a.2:later)", false},
+		{"knows(division)", true},
+		{"knows(algebra)", false},
+		{"chose(a.0:start, 2)", true},
+		{"chose(a.0:start, 1)", false},
+	}
+
+	for _, tt := range tests {
+		if got := condition.MustParse(tt.expr).Eval(s); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
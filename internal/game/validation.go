@@ -3,26 +3,91 @@ package game
 import (
 	"fmt"
 	"html/template"
+	"math"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
+// Validator checks a player's typed answer
+type Validator interface {
+	Validate(input string) ValidationResult
+}
+
 // ResponseValidator checks free-text answers for open response questions
 type ResponseValidator struct {
-	AcceptedKeywords []string // Keywords that must be present
-	RequiredCount    int      // Minimum number of keywords that must match
-	CaseSensitive    bool     // Whether matching is case-sensitive
-	AllowPartial     bool     // Allow partial word matches
+	AcceptedKeywords []string       // Keywords that must be present
+	Groups           []KeywordGroup // Concepts with alternative wordings, each counted once
+	Forbidden        []string       // Keywords that make an answer wrong wherever they appear
+	Patterns         []PatternRule  // Regular expressions the answer must (or must not) match
+	RequiredCount    int            // Minimum number of keywords (or groups) that must match
+	CaseSensitive    bool           // Whether matching is case-sensitive
+	AllowPartial     bool           // Allow partial word matches
+	Stem             bool           // Match inflections such as divide/divided/dividing
+	Fuzzy            bool           // Accept misspellings within an edit distance
+
+	// MaxDistance is the default number of edits a fuzzy match may need
+	// Zero picks a threshold from the keyword's length (see defaultDistance)
+	MaxDistance int
+	Distances   map[string]int // Per-keyword thresholds, overriding MaxDistance
+}
+
+// FuzzyMatch records a keyword that was accepted despite a misspelling
+type FuzzyMatch struct {
+	Keyword  string // Keyword as configured
+	Input    string // What the player actually typed
+	Distance int    // Edits between the two
 }
 
 // ValidationResult contains feedback for the player
 type ValidationResult struct {
-	Correct        bool          // Whether the answer is correct
-	MatchedWords   []string      // Keywords that were found
-	MissingWords   []string      // Keywords that were not found
-	Score          float64       // 0.0 to 1.0, percentage of keywords matched
-	AnnotatedInput template.HTML // User's input with matched keywords highlighted
+	Correct        bool            // Whether the answer is correct
+	MatchedWords   []string        // Keywords that were found
+	MissingWords   []string        // Keywords that were not found
+	Score          float64         // 0.0 to 1.0, percentage of keywords matched
+	AnnotatedInput template.HTML   // User's input with matched keywords highlighted
+	FuzzyMatches   []FuzzyMatch    // Matches accepted only through fuzzy matching
+	ForbiddenWords []string        // Forbidden keywords found in the answer
+	Patterns       []PatternResult // Outcome of each pattern rule, in order
+	Rejection      RejectionReason // Why a numeric or expression answer was rejected
+}
+
+// RejectionReason explains why a numeric or expression answer was not accepted
+type RejectionReason string
+
+const (
+	RejectUnparseable RejectionReason = "unparseable"  // No number could be read
+	RejectWrongValue  RejectionReason = "wrong_value"  // A number, but not the answer
+	RejectWrongUnit   RejectionReason = "wrong_unit"   // A unit that cannot be converted to the answer's
+	RejectMissingUnit RejectionReason = "missing_unit" // The answer needs a unit
+
+	RejectBadExpression  RejectionReason = "bad_expression"  // No expression could be read
+	RejectNotEquivalent  RejectionReason = "not_equivalent"  // An expression, but not equal to the answer
+	RejectArithmeticSlip RejectionReason = "arithmetic_slip" // One number wrong in an otherwise right answer
+)
+
+// Message explains the rejection to the player without giving the answer away
+func (r RejectionReason) Message() string {
+	switch r {
+	case RejectUnparseable:
+		return "That doesn't look like a number. Try digits, a decimal, or a fraction like 3/4."
+	case RejectWrongUnit:
+		return "Check your units. That unit doesn't fit this question."
+	case RejectMissingUnit:
+		return "Include the unit with your answer."
+	case RejectWrongValue:
+		return "That's a number, but not the right one. Check your working."
+	case RejectBadExpression:
+		return "That doesn't look like an expression. Check your brackets and operators."
+	case RejectNotEquivalent:
+		return "That expression isn't equal to the answer. Try simplifying it step by step."
+	case RejectArithmeticSlip:
+		return "Nearly! The form is right, but there's a slip in the arithmetic."
+	}
+	return ""
 }
 
 // NewValidator creates a validator with default settings
@@ -37,7 +102,8 @@ func NewValidator(keywords []string, required int) *ResponseValidator {
 
 // Validate checks user input against accepted keywords
 func (v *ResponseValidator) Validate(input string) ValidationResult {
-	if len(v.AcceptedKeywords) == 0 {
+	groups := v.groups()
+	if len(groups) == 0 && len(v.Forbidden) == 0 && len(v.Patterns) == 0 {
 		return ValidationResult{
 			Correct: true,
 			Score:   1.0,
@@ -45,68 +111,231 @@ func (v *ResponseValidator) Validate(input string) ValidationResult {
 	}
 
 	normalized := normalize(input, v.CaseSensitive)
+	words := inputWords(input)
 
 	var matched []string
 	var missing []string
+	var fuzzy []FuzzyMatch
+	var forbidden []string
+	typed := make(map[string]string) // Text to highlight -> CSS class
+
+	for _, group := range groups {
+		found := false
+		for _, alt := range group.Alternatives {
+			if text, ok := v.findKeyword(normalized, words, alt); ok {
+				typed[text] = "match-correct"
+				found = true
+				break
+			}
+		}
 
-	for _, keyword := range v.AcceptedKeywords {
-		normalizedKeyword := normalize(keyword, v.CaseSensitive)
-
-		if v.AllowPartial {
-			// Check if keyword appears anywhere as a partial match
-			if strings.Contains(normalized, normalizedKeyword) {
-				matched = append(matched, keyword)
-			} else {
-				missing = append(missing, keyword)
+		if !found && v.Fuzzy {
+			if match, ok := v.closestAlternative(words, group); ok {
+				typed[match.Input] = "match-fuzzy"
+				fuzzy = append(fuzzy, match)
+				found = true
 			}
+		}
+
+		if found {
+			matched = append(matched, group.label())
 		} else {
-			// Exact word boundary matching
-			if containsWord(normalized, normalizedKeyword) {
-				matched = append(matched, keyword)
-			} else {
-				missing = append(missing, keyword)
-			}
+			missing = append(missing, group.label())
+		}
+	}
+
+	// Forbidden keywords are never matched fuzzily, so near-misses are not penalised
+	for _, keyword := range v.Forbidden {
+		if text, ok := v.findKeyword(normalized, words, keyword); ok {
+			typed[text] = "match-forbidden"
+			forbidden = append(forbidden, keyword)
 		}
 	}
 
-	score := float64(len(matched)) / float64(len(v.AcceptedKeywords))
-	correct := len(matched) >= v.RequiredCount
+	patterns, required, satisfied, violated := v.checkPatterns(input, typed)
+
+	// Required patterns count toward the score like keywords
+	score := 1.0
+	if total := len(groups) + required; total > 0 {
+		score = float64(len(matched)+satisfied) / float64(total)
+	}
+	correct := len(matched) >= v.RequiredCount && satisfied == required
+	if len(forbidden) > 0 || violated {
+		correct = false
+		score = 0
+	}
 
 	return ValidationResult{
 		Correct:        correct,
 		MatchedWords:   matched,
 		MissingWords:   missing,
 		Score:          score,
-		AnnotatedInput: v.annotateMatches(input, matched),
+		AnnotatedInput: annotateMatches(input, typed),
+		FuzzyMatches:   fuzzy,
+		ForbiddenWords: forbidden,
+		Patterns:       patterns,
 	}
 }
 
-// annotateMatches highlights matched keywords in the user's input
-func (v *ResponseValidator) annotateMatches(input string, matches []string) template.HTML {
-	if len(matches) == 0 {
-		return template.HTML(input)
+// findKeyword looks for a keyword in the input and returns the text that matched
+func (v *ResponseValidator) findKeyword(normalized string, words []string, keyword string) (string, bool) {
+	if v.AllowPartial {
+		// Check if keyword appears anywhere as a partial match
+		return keyword, strings.Contains(normalized, normalize(keyword, v.CaseSensitive))
 	}
+	// Exact word boundary matching
+	return v.findPhrase(words, keyword)
+}
 
-	// Build a regex pattern that matches any of the keywords (case-insensitive)
-	// Sort by length (longest first) to avoid partial replacements
-	sortedMatches := make([]string, len(matches))
-	copy(sortedMatches, matches)
-	sortByLength(sortedMatches)
+// closestAlternative finds the group alternative nearest to something the player typed
+func (v *ResponseValidator) closestAlternative(words []string, group KeywordGroup) (FuzzyMatch, bool) {
+	var best FuzzyMatch
+	found := false
+	for _, alt := range group.Alternatives {
+		word, distance, ok := closestWord(words, normalize(alt, v.CaseSensitive), v.threshold(alt, group.Name), v.CaseSensitive)
+		if ok && (!found || distance < best.Distance) {
+			best = FuzzyMatch{Keyword: alt, Input: word, Distance: distance}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// threshold returns the edit distance allowed for a keyword
+// A limit set on the keyword itself wins over one set on its group
+func (v *ResponseValidator) threshold(keyword, group string) int {
+	if d, ok := v.Distances[keyword]; ok {
+		return d
+	}
+	if d, ok := v.Distances[group]; ok {
+		return d
+	}
+	if v.MaxDistance > 0 {
+		return v.MaxDistance
+	}
+	return defaultDistance(keyword)
+}
+
+// defaultDistance scales the allowed edits with keyword length
+// Short words get none, since "war" is one edit from "car" and "was"
+func defaultDistance(keyword string) int {
+	switch n := utf8.RuneCountInString(keyword); {
+	case n <= 4:
+		return 0
+	case n <= 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// closestWord finds the run of input words nearest to keyword within maxDistance
+// Multi-word keywords are compared against runs of the same number of words
+// Returns the matched words as typed and their distance
+func closestWord(words []string, keyword string, maxDistance int, caseSensitive bool) (string, int, bool) {
+	if maxDistance <= 0 {
+		return "", 0, false
+	}
+	size := len(strings.Fields(keyword))
+
+	best, bestDistance := "", maxDistance+1
+	for i := 0; i+size <= len(words); i++ {
+		typed := strings.Join(words[i:i+size], " ")
+		candidate := typed
+		if !caseSensitive {
+			candidate = strings.ToLower(candidate)
+		}
+		if d := editDistance(candidate, keyword); d < bestDistance {
+			best, bestDistance = typed, d
+		}
+	}
+	if best == "" {
+		return "", 0, false
+	}
+	return best, bestDistance, true
+}
+
+// editDistance counts the insertions, deletions, substitutions, and adjacent
+// transpositions needed to turn a into b (optimal string alignment distance)
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	// Three rolling rows: two back (for transpositions), previous, and current
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(rb)]
+}
 
-	result := input
-	for _, match := range sortedMatches {
-		// Use word boundaries for exact matching
-		pattern := `(?i)\b` + regexp.QuoteMeta(match) + `\b`
-		re := regexp.MustCompile(pattern)
+// annotateMatches highlights matched text in the user's input
+// classes maps each piece of typed text to the CSS class it is marked with
+// The input is HTML-escaped first since it comes straight from the player
+func annotateMatches(input string, classes map[string]string) template.HTML {
+	escaped := template.HTMLEscapeString(input)
+	if len(classes) == 0 {
+		return template.HTML(escaped)
+	}
+
+	// Sort by length (longest first) so longer phrases win over words inside them
+	sortedMatches := make([]string, 0, len(classes))
+	lookup := make(map[string]string, len(classes))
+	for m, class := range classes {
+		sortedMatches = append(sortedMatches, m)
+		lookup[strings.ToLower(template.HTMLEscapeString(m))] = class
+	}
+	sort.Strings(sortedMatches)
+	sortByLength(sortedMatches)
 
-		result = re.ReplaceAllStringFunc(result, func(found string) string {
-			return fmt.Sprintf(`<mark class="match-correct">%s</mark>`, found)
-		})
+	// Replace in a single pass so highlights never nest inside each other
+	alternatives := make([]string, len(sortedMatches))
+	for i, match := range sortedMatches {
+		alternatives[i] = boundedPattern(template.HTMLEscapeString(match))
 	}
+	re := regexp.MustCompile(`(?i)(?:` + strings.Join(alternatives, "|") + `)`)
+
+	result := re.ReplaceAllStringFunc(escaped, func(found string) string {
+		return fmt.Sprintf(`<mark class="%s">%s</mark>`, lookup[strings.ToLower(found)], found)
+	})
 
 	return template.HTML(result)
 }
 
+// boundedPattern quotes text for a regex, adding word boundaries at either end
+// that starts or finishes with a word character so matches are whole words
+func boundedPattern(text string) string {
+	pattern := regexp.QuoteMeta(text)
+	if r, _ := utf8.DecodeRuneInString(text); isWordChar(r) {
+		pattern = `\b` + pattern
+	}
+	if r, _ := utf8.DecodeLastRuneInString(text); isWordChar(r) {
+		pattern += `\b`
+	}
+	return pattern
+}
+
+// isWordChar matches the characters \b treats as part of a word
+func isWordChar(r rune) bool {
+	return r == '_' || ('0' <= r && r <= '9') || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+}
+
 // normalize cleans up input text for comparison
 func normalize(s string, caseSensitive bool) string {
 	// Remove extra whitespace
@@ -123,20 +352,6 @@ func normalize(s string, caseSensitive bool) string {
 	return s
 }
 
-// containsWord checks if a word exists with word boundaries
-func containsWord(text, word string) bool {
-	// Simple word boundary check - matches if word appears as a complete word
-	words := strings.Fields(text)
-	for _, w := range words {
-		// Remove trailing punctuation
-		w = strings.TrimRight(w, ".,!?;:")
-		if w == word {
-			return true
-		}
-	}
-	return false
-}
-
 // sortByLength sorts strings by length (longest first)
 func sortByLength(strs []string) {
 	// Bubble sort is fine for small arrays
@@ -151,46 +366,82 @@ func sortByLength(strs []string) {
 
 // NumericValidator validates numeric responses
 type NumericValidator struct {
-	AcceptedValues []float64 // Accepted numeric values
-	Tolerance      float64   // Acceptable margin of error
+	AcceptedValues    []float64      // Accepted numeric values
+	Tolerance         float64        // Acceptable margin of error
+	RelativeTolerance float64        // Acceptable error as a fraction of the answer (0.05 = 5%)
+	Ranges            []NumericRange // Accepted ranges, in addition to AcceptedValues
+	Unit              string         // Unit the answers are given in, e.g. "cm" (empty = a bare number)
+	RequireUnit       bool           // Reject answers that leave the unit off
+}
+
+// NumericRange is an inclusive range of accepted values
+type NumericRange struct {
+	Min, Max float64
+}
+
+// Validate implements Validator for numeric answers
+func (v *NumericValidator) Validate(input string) ValidationResult {
+	return v.ValidateNumeric(input)
 }
 
 // ValidateNumeric checks if a numeric input is correct
+// Answers in a convertible unit (mm for cm, % for a ratio) are converted first
+// A percentage against a bare answer is accepted as a ratio or as a count of percent.
 func (v *NumericValidator) ValidateNumeric(input string) ValidationResult {
 	input = strings.TrimSpace(input)
 
-	// Try to parse as number
-	var inputNum float64
-	_, err := fmt.Sscanf(input, "%f", &inputNum)
+	q, err := ParseQuantity(input)
 	if err != nil {
-		return ValidationResult{
-			Correct: false,
-			Score:   0.0,
-		}
+		return ValidationResult{Rejection: RejectUnparseable}
 	}
 
-	// Check if input matches any accepted value within tolerance
-	for _, accepted := range v.AcceptedValues {
-		diff := inputNum - accepted
-		if diff < 0 {
-			diff = -diff
-		}
+	if q.Unit == "" && v.Unit != "" && v.RequireUnit {
+		return ValidationResult{Rejection: RejectMissingUnit}
+	}
 
-		if diff <= v.Tolerance {
-			return ValidationResult{
-				Correct:        true,
-				Score:          1.0,
-				AnnotatedInput: template.HTML(input),
-			}
+	// Without a unit the number is taken to be in the answer's unit
+	values := []float64{q.Value}
+	if q.Unit != "" {
+		converted, ok := convert(q, v.Unit)
+		if !ok {
+			return ValidationResult{Rejection: RejectWrongUnit}
 		}
+		values = []float64{converted}
+		// Only a percentage converts to a bare answer, which may be the
+		// ratio (25% = 0.25) or the number of percent (25)
+		if v.Unit == "" {
+			values = append(values, q.Value)
+		}
+	}
+
+	if !slices.ContainsFunc(values, v.accepts) {
+		return ValidationResult{Rejection: RejectWrongValue}
 	}
 
 	return ValidationResult{
-		Correct: false,
-		Score:   0.0,
+		Correct:        true,
+		Score:          1.0,
+		AnnotatedInput: template.HTML(template.HTMLEscapeString(input)),
 	}
 }
 
+// accepts reports whether value matches an accepted value or falls in a range
+func (v *NumericValidator) accepts(value float64) bool {
+	// Check if input matches any accepted value within tolerance
+	for _, accepted := range v.AcceptedValues {
+		tolerance := math.Max(v.Tolerance, v.RelativeTolerance*math.Abs(accepted))
+		if closeTo(value, accepted, tolerance) {
+			return true
+		}
+	}
+	for _, r := range v.Ranges {
+		if value >= r.Min-v.Tolerance && value <= r.Max+v.Tolerance {
+			return true
+		}
+	}
+	return false
+}
+
 // MultipleChoiceValidator validates multiple choice answers
 type MultipleChoiceValidator struct {
 	CorrectIndices []int // Zero-based indices of correct choices
@@ -206,9 +457,14 @@ func (v *MultipleChoiceValidator) ValidateChoice(selected []int) ValidationResul
 		}
 	}
 
-	// Check if all selected choices are correct
+	// Check if all selected choices are correct; repeats count once
 	matchCount := 0
+	seen := make(map[int]bool, len(selected))
 	for _, sel := range selected {
+		if seen[sel] {
+			continue
+		}
+		seen[sel] = true
 		correct := false
 		for _, correctIdx := range v.CorrectIndices {
 			if sel == correctIdx {
@@ -124,6 +124,167 @@ func TestResponseValidator_AnnotateMatches(t *testing.T) {
 	}
 }
 
+func TestResponseValidator_AnnotateEscapesInput(t *testing.T) {
+	v := NewValidator([]string{"division"}, 1)
+	result := v.Validate("<script>alert(1)</script> division")
+
+	annotated := string(result.AnnotatedInput)
+
+	if containsString(annotated, "<script>") {
+		t.Errorf("Expected input to be escaped, got: %s", annotated)
+	}
+
+	if !containsString(annotated, `<mark class="match-correct">division</mark>`) {
+		t.Errorf("Expected 'division' to be highlighted, got: %s", annotated)
+	}
+}
+
+func TestResponseValidator_Fuzzy(t *testing.T) {
+	tests := []struct {
+		name        string
+		keywords    []string
+		maxDistance int
+		distances   map[string]int
+		input       string
+		wantCorrect bool
+		wantFuzzy   []FuzzyMatch
+	}{
+		{
+			name:        "exact match is not fuzzy",
+			keywords:    []string{"division"},
+			input:       "long division",
+			wantCorrect: true,
+		},
+		{
+			name:        "missing letter",
+			keywords:    []string{"division"},
+			input:       "I used divison",
+			wantCorrect: true,
+			wantFuzzy:   []FuzzyMatch{{Keyword: "division", Input: "divison", Distance: 1}},
+		},
+		{
+			name:        "transposed letters count once",
+			keywords:    []string{"treaty"},
+			input:       "the traety was signed",
+			wantCorrect: true,
+			wantFuzzy:   []FuzzyMatch{{Keyword: "treaty", Input: "traety", Distance: 1}},
+		},
+		{
+			name:        "case and punctuation ignored",
+			keywords:    []string{"Versailles"},
+			input:       "The Treaty of Versaille.",
+			wantCorrect: true,
+			wantFuzzy:   []FuzzyMatch{{Keyword: "Versailles", Input: "Versaille", Distance: 1}},
+		},
+		{
+			name:        "multi-word keyword",
+			keywords:    []string{"French Revolution"},
+			input:       "the frensh revolution began",
+			wantCorrect: true,
+			wantFuzzy:   []FuzzyMatch{{Keyword: "French Revolution", Input: "frensh revolution", Distance: 1}},
+		},
+		{
+			name:        "short words need exact spelling",
+			keywords:    []string{"war"},
+			input:       "a great car",
+			wantCorrect: false,
+		},
+		{
+			name:        "too far for default threshold",
+			keywords:    []string{"division"},
+			input:       "divsn",
+			wantCorrect: false,
+		},
+		{
+			name:        "max distance raises threshold",
+			keywords:    []string{"division"},
+			maxDistance: 3,
+			input:       "divsn",
+			wantCorrect: true,
+			wantFuzzy:   []FuzzyMatch{{Keyword: "division", Input: "divsn", Distance: 3}},
+		},
+		{
+			name:        "per-keyword threshold overrides default",
+			keywords:    []string{"division"},
+			distances:   map[string]int{"division": 0},
+			input:       "divison",
+			wantCorrect: false,
+		},
+		{
+			name:        "closest of several candidates",
+			keywords:    []string{"algebra"},
+			input:       "algebro or algebra-ish algbra",
+			wantCorrect: true,
+			wantFuzzy:   []FuzzyMatch{{Keyword: "algebra", Input: "algebro", Distance: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewValidator(tt.keywords, 1)
+			v.Fuzzy = true
+			v.MaxDistance = tt.maxDistance
+			v.Distances = tt.distances
+			result := v.Validate(tt.input)
+
+			if result.Correct != tt.wantCorrect {
+				t.Errorf("Correct = %v, want %v", result.Correct, tt.wantCorrect)
+			}
+			if len(result.FuzzyMatches) != len(tt.wantFuzzy) {
+				t.Fatalf("FuzzyMatches = %v, want %v", result.FuzzyMatches, tt.wantFuzzy)
+			}
+			for i, want := range tt.wantFuzzy {
+				if result.FuzzyMatches[i] != want {
+					t.Errorf("FuzzyMatches[%d] = %+v, want %+v", i, result.FuzzyMatches[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestResponseValidator_FuzzyDisabled(t *testing.T) {
+	v := NewValidator([]string{"division"}, 1)
+	if v.Validate("divison").Correct {
+		t.Error("Expected misspelling to be rejected without fuzzy matching")
+	}
+}
+
+func TestResponseValidator_AnnotateFuzzy(t *testing.T) {
+	v := NewValidator([]string{"division", "math"}, 2)
+	v.Fuzzy = true
+	result := v.Validate("math and divison")
+
+	annotated := string(result.AnnotatedInput)
+	if !containsString(annotated, `<mark class="match-fuzzy">divison</mark>`) {
+		t.Errorf("Expected 'divison' to be marked fuzzy, got: %s", annotated)
+	}
+	if !containsString(annotated, `<mark class="match-correct">math</mark>`) {
+		t.Errorf("Expected 'math' to be highlighted, got: %s", annotated)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"division", "division", 0},
+		{"divison", "division", 1},
+		{"kitten", "sitting", 3},
+		{"traety", "treaty", 1},
+		{"café", "cafe", 1},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
 func TestNumericValidator_ValidateNumeric(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -191,6 +352,7 @@ func TestMultipleChoiceValidator_ValidateChoice(t *testing.T) {
 		allowMultiple  bool
 		selected       []int
 		wantCorrect    bool
+		wantScore      float64
 	}{
 		{
 			name:           "single correct choice",
@@ -198,6 +360,7 @@ func TestMultipleChoiceValidator_ValidateChoice(t *testing.T) {
 			allowMultiple:  false,
 			selected:       []int{2},
 			wantCorrect:    true,
+			wantScore:      1,
 		},
 		{
 			name:           "single wrong choice",
@@ -219,6 +382,7 @@ func TestMultipleChoiceValidator_ValidateChoice(t *testing.T) {
 			allowMultiple:  true,
 			selected:       []int{1, 3},
 			wantCorrect:    true,
+			wantScore:      1,
 		},
 		{
 			name:           "missing one correct choice",
@@ -226,6 +390,7 @@ func TestMultipleChoiceValidator_ValidateChoice(t *testing.T) {
 			allowMultiple:  true,
 			selected:       []int{1},
 			wantCorrect:    false,
+			wantScore:      0.5,
 		},
 		{
 			name:           "includes wrong choice",
@@ -234,6 +399,14 @@ func TestMultipleChoiceValidator_ValidateChoice(t *testing.T) {
 			selected:       []int{1, 2, 3},
 			wantCorrect:    false,
 		},
+		{
+			name:           "repeated selection counts once",
+			correctIndices: []int{1, 3},
+			allowMultiple:  true,
+			selected:       []int{1, 1},
+			wantCorrect:    false,
+			wantScore:      0.5,
+		},
 	}
 
 	for _, tt := range tests {
@@ -247,6 +420,9 @@ func TestMultipleChoiceValidator_ValidateChoice(t *testing.T) {
 			if result.Correct != tt.wantCorrect {
 				t.Errorf("Correct = %v, want %v", result.Correct, tt.wantCorrect)
 			}
+			if result.Score != tt.wantScore {
+				t.Errorf("Score = %v, want %v", result.Score, tt.wantScore)
+			}
 		})
 	}
 }
@@ -0,0 +1,120 @@
+// Package genie implements the genie in a bottle: hints that are free during
+// the tutorial and then cost an escalating cooldown, as described in
+// GAME_FEATURES.md
+package genie
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrResting is returned by Rub while the genie is cooling down
+var ErrResting = errors.New("the genie is resting")
+
+// cooldownUnits are the steps of every schedule: each paid rub waits one
+// unit longer than the last, multiplied by the schedule's base
+var cooldownUnits = []struct {
+	size time.Duration
+	name string
+}{
+	{time.Second, "second"},
+	{time.Minute, "minute"},
+	{time.Hour, "hour"},
+	{24 * time.Hour, "day"},
+	{30 * 24 * time.Hour, "month"},
+	{365 * 24 * time.Hour, "year"},
+}
+
+// Schedule configures how often the genie can be rubbed
+type Schedule struct {
+	Base     int // Multiplier for each step: 5 gives 5s, 5m, 5h, 5d, 5mo, 5y
+	FreeRubs int // Tutorial rubs with no cooldown
+}
+
+// DefaultSchedule is the 5x variant with three free tutorial rubs
+var DefaultSchedule = Schedule{Base: 5, FreeRubs: 3}
+
+// ParseSchedule reads a schedule variant: "2x", "3x", or "5x"
+func ParseSchedule(s string) (Schedule, error) {
+	base, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "x"))
+	if err != nil || (base != 2 && base != 3 && base != 5) {
+		return Schedule{}, fmt.Errorf("unknown hint schedule %q (must be 2x, 3x, or 5x)", s)
+	}
+	return Schedule{Base: base, FreeRubs: DefaultSchedule.FreeRubs}, nil
+}
+
+// String formats the schedule as its variant name, e.g. "5x"
+func (s Schedule) String() string {
+	return fmt.Sprintf("%dx", s.Base)
+}
+
+// Cooldown returns the wait after the nth paid rub, counting from 1
+// Rubs beyond the last step keep the longest wait
+func (s Schedule) Cooldown(paid int) time.Duration {
+	if paid <= 0 {
+		return 0
+	}
+	step := min(paid, len(cooldownUnits)) - 1
+	return time.Duration(s.Base) * cooldownUnits[step].size
+}
+
+// Bottle is a player's genie, stored in their session
+type Bottle struct {
+	Rubs    int   `json:"rubs,omitempty"`     // Times rubbed, including free rubs
+	ReadyAt int64 `json:"ready_at,omitempty"` // Unix time the genie can next be rubbed
+}
+
+// FreeLeft returns how many free tutorial rubs remain
+func (b Bottle) FreeLeft(s Schedule) int {
+	return max(s.FreeRubs-b.Rubs, 0)
+}
+
+// Wait returns how long until the genie can be rubbed, or 0 if it is ready
+func (b Bottle) Wait(now time.Time) time.Duration {
+	if b.ReadyAt == 0 {
+		return 0
+	}
+	return max(time.Unix(b.ReadyAt, 0).Sub(now), 0)
+}
+
+// NextCooldown returns the wait the next rub would start
+func (b Bottle) NextCooldown(s Schedule) time.Duration {
+	return s.Cooldown(b.Rubs + 1 - s.FreeRubs)
+}
+
+// Rub summons the genie, starting a cooldown once the free rubs are used up
+// Returns ErrResting if the previous cooldown has not finished
+func (b *Bottle) Rub(s Schedule, now time.Time) error {
+	if b.Wait(now) > 0 {
+		return ErrResting
+	}
+	cooldown := b.NextCooldown(s)
+	b.Rubs++
+	b.ReadyAt = 0
+	if cooldown > 0 {
+		b.ReadyAt = now.Add(cooldown).Unix()
+	}
+	return nil
+}
+
+// Describe formats a wait in its largest whole unit, rounding up so the
+// genie is never ready earlier than promised: "5 minutes", "1 day"
+func Describe(d time.Duration) string {
+	if d <= 0 {
+		return "now"
+	}
+	unit := cooldownUnits[0]
+	for _, u := range cooldownUnits {
+		if d >= u.size {
+			unit = u
+		}
+	}
+	n := int((d + unit.size - 1) / unit.size)
+	if n == 1 {
+		return "1 " + unit.name
+	}
+	return fmt.Sprintf("%d %ss", n, unit.name)
+}
@@ -0,0 +1,119 @@
+package genie
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	for _, tt := range []struct {
+		input string
+		base  int
+	}{{"2x", 2}, {"3x", 3}, {"5X", 5}, {" 5 ", 5}} {
+		s, err := ParseSchedule(tt.input)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q) error: %v", tt.input, err)
+			continue
+		}
+		if s.Base != tt.base || s.FreeRubs != 3 {
+			t.Errorf("ParseSchedule(%q) = %+v, want base %d with 3 free rubs", tt.input, s, tt.base)
+		}
+	}
+
+	for _, input := range []string{"", "4x", "x", "fast"} {
+		if _, err := ParseSchedule(input); err == nil {
+			t.Errorf("ParseSchedule(%q): expected error", input)
+		}
+	}
+}
+
+func TestSchedule_Cooldown(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		schedule Schedule
+		paid     int
+		want     time.Duration
+	}{
+		{DefaultSchedule, 0, 0},
+		{DefaultSchedule, 1, 5 * time.Second},
+		{DefaultSchedule, 2, 5 * time.Minute},
+		{DefaultSchedule, 3, 5 * time.Hour},
+		{DefaultSchedule, 4, 5 * day},
+		{DefaultSchedule, 5, 5 * 30 * day},
+		{DefaultSchedule, 6, 5 * 365 * day},
+		{DefaultSchedule, 9, 5 * 365 * day},
+		{Schedule{Base: 2}, 2, 2 * time.Minute},
+		{Schedule{Base: 3}, 3, 3 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := tt.schedule.Cooldown(tt.paid); got != tt.want {
+			t.Errorf("%s Cooldown(%d) = %v, want %v", tt.schedule, tt.paid, got, tt.want)
+		}
+	}
+}
+
+func TestBottle_Rub(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	var b Bottle
+
+	// The tutorial rubs are free
+	for i := range 3 {
+		if err := b.Rub(DefaultSchedule, now); err != nil {
+			t.Fatalf("free rub %d: %v", i+1, err)
+		}
+		if b.Wait(now) != 0 {
+			t.Fatalf("free rub %d started a cooldown", i+1)
+		}
+	}
+	if b.FreeLeft(DefaultSchedule) != 0 {
+		t.Errorf("FreeLeft = %d, want 0", b.FreeLeft(DefaultSchedule))
+	}
+
+	// Then each rub starts a longer cooldown
+	if got := b.NextCooldown(DefaultSchedule); got != 5*time.Second {
+		t.Errorf("NextCooldown = %v, want 5s", got)
+	}
+	if err := b.Rub(DefaultSchedule, now); err != nil {
+		t.Fatalf("first paid rub: %v", err)
+	}
+	if got := b.Wait(now); got != 5*time.Second {
+		t.Errorf("Wait = %v, want 5s", got)
+	}
+	if err := b.Rub(DefaultSchedule, now.Add(time.Second)); !errors.Is(err, ErrResting) {
+		t.Errorf("rub while resting: error = %v, want ErrResting", err)
+	}
+
+	later := now.Add(5 * time.Second)
+	if err := b.Rub(DefaultSchedule, later); err != nil {
+		t.Fatalf("second paid rub: %v", err)
+	}
+	if got := b.Wait(later); got != 5*time.Minute {
+		t.Errorf("Wait = %v, want 5m", got)
+	}
+	if b.Rubs != 5 {
+		t.Errorf("Rubs = %d, want 5", b.Rubs)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "now"},
+		{500 * time.Millisecond, "1 second"},
+		{5 * time.Second, "5 seconds"},
+		{4*time.Minute + 30*time.Second, "5 minutes"},
+		{time.Hour, "1 hour"},
+		{5 * 24 * time.Hour, "5 days"},
+		{2 * 365 * 24 * time.Hour, "2 years"},
+	}
+
+	for _, tt := range tests {
+		if got := Describe(tt.d); got != tt.want {
+			t.Errorf("Describe(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Package grading turns validator scores into chapter grades on the
+// F/C/B/A/S scale described in GAME_FEATURES.md
+package grading
+
+import "math"
+
+// Letter is a grade on the five-tier scale
+type Letter string
+
+const (
+	F Letter = "F" // Failure: below 60%
+	C Letter = "C" // Class Average: 60-74%
+	B Letter = "B" // Best: 75-84%, and the highest grade once hints are used
+	A Letter = "A" // Ace: 85-94%
+	S Letter = "S" // Superior: 95-100%
+)
+
+// scale lists the letters from worst to best with the percentage each needs
+var scale = []struct {
+	letter  Letter
+	minimum float64
+	name    string
+}{
+	{F, 0, "Failure"},
+	{C, 60, "Class Average"},
+	{B, 75, "Best"},
+	{A, 85, "Ace"},
+	{S, 95, "Superior"},
+}
+
+// HintCap is the most a question answered with a hint can score
+const HintCap = 0.85
+
+// LetterFor returns the letter for a percentage from 0 to 100
+func LetterFor(percent float64) Letter {
+	letter := F
+	for _, step := range scale {
+		// The slack keeps 95 from becoming 94.99999 and missing an S
+		if percent+1e-9 >= step.minimum {
+			letter = step.letter
+		}
+	}
+	return letter
+}
+
+// rank orders letters from F (0) to S (4); unknown letters rank below F
+func (l Letter) rank() int {
+	for i, step := range scale {
+		if step.letter == l {
+			return i
+		}
+	}
+	return -1
+}
+
+// Better reports whether l is a higher grade than other
+func (l Letter) Better(other Letter) bool {
+	return l.rank() > other.rank()
+}
+
+// Passing reports whether the grade is enough to progress (C or above)
+func (l Letter) Passing() bool {
+	return l.rank() >= C.rank()
+}
+
+// Name returns the long name of the grade, such as "Ace"
+func (l Letter) Name() string {
+	if i := l.rank(); i >= 0 {
+		return scale[i].name
+	}
+	return ""
+}
+
+// Answer is how a player did on one graded question
+type Answer struct {
+	Score    float64 `json:"score"`              // 0.0 to 1.0, from the validator
+	Hinted   bool    `json:"hinted,omitempty"`   // A hint was used, so the score is capped
+	Spelling int     `json:"spelling,omitempty"` // Words accepted despite being misspelled
+}
+
+// Capped returns the score after the hint cap
+func (a Answer) Capped() float64 {
+	score := math.Max(0, math.Min(a.Score, 1))
+	if a.Hinted {
+		score = math.Min(score, HintCap)
+	}
+	return score
+}
+
+// Question is an answered question with its weight in the chapter grade
+type Question struct {
+	SceneID string
+	Weight  float64 // Relative importance; 0 leaves the question out of the grade
+	Answer  Answer
+}
+
+// Grade summarises a chapter
+type Grade struct {
+	Letter    Letter  `json:"letter"`
+	Percent   float64 `json:"percent"`            // Weighted score from 0 to 100, after hint caps
+	Correct   int     `json:"correct"`            // Questions with a full score
+	Total     int     `json:"total"`              // Questions counted toward the grade
+	UsedHints bool    `json:"hints,omitempty"`    // Any hint caps the letter at B
+	Spelling  int     `json:"spelling,omitempty"` // Misspelled words accepted across the chapter
+}
+
+// Calculate grades a chapter from its answered questions
+// A chapter with no weighted questions has no grade (Total is 0)
+func Calculate(questions []Question) Grade {
+	var g Grade
+	var earned, possible float64
+	for _, q := range questions {
+		if q.Weight <= 0 {
+			continue
+		}
+		g.Total++
+		if q.Answer.Score >= 1 {
+			g.Correct++
+		}
+		g.UsedHints = g.UsedHints || q.Answer.Hinted
+		g.Spelling += q.Answer.Spelling
+		earned += q.Weight * q.Answer.Capped()
+		possible += q.Weight
+	}
+	if possible == 0 {
+		return g
+	}
+
+	g.Percent = 100 * earned / possible
+	g.Letter = LetterFor(g.Percent)
+	if g.UsedHints && g.Letter.Better(B) {
+		g.Letter = B
+	}
+	return g
+}
+
+// CalculateGrade grades equally weighted questions of which correct were right
+func CalculateGrade(correct, total int, usedHints bool) Grade {
+	questions := make([]Question, total)
+	for i := range questions {
+		questions[i] = Question{Weight: 1, Answer: Answer{Hinted: usedHints}}
+		if i < correct {
+			questions[i].Answer.Score = 1
+		}
+	}
+	return Calculate(questions)
+}
+
+// Record holds the best letter ever earned in each chapter
+type Record map[string]Letter
+
+// Update records a grade for a chapter if it beats the best so far
+// Returns true if the grade is a new best
+func (r Record) Update(chapter string, letter Letter) bool {
+	if best, ok := r[chapter]; ok && !letter.Better(best) {
+		return false
+	}
+	r[chapter] = letter
+	return true
+}
@@ -0,0 +1,156 @@
+package grading
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLetterFor(t *testing.T) {
+	tests := []struct {
+		percent float64
+		want    Letter
+	}{
+		{0, F},
+		{59.9, F},
+		{60, C},
+		{74.9, C},
+		{75, B},
+		{84.9, B},
+		{85, A},
+		{94.9, A},
+		{95, S},
+		{100, S},
+		{100 * 19 / 20.0, S},
+	}
+
+	for _, tt := range tests {
+		if got := LetterFor(tt.percent); got != tt.want {
+			t.Errorf("LetterFor(%v) = %s, want %s", tt.percent, got, tt.want)
+		}
+	}
+}
+
+func TestLetter(t *testing.T) {
+	if !S.Better(A) || A.Better(S) || B.Better(B) {
+		t.Error("Better does not follow the F < C < B < A < S order")
+	}
+	if !C.Passing() || F.Passing() {
+		t.Error("Passing should start at C")
+	}
+	if got := A.Name(); got != "Ace" {
+		t.Errorf("A.Name() = %q, want Ace", got)
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		name        string
+		questions   []Question
+		wantLetter  Letter
+		wantPercent float64
+		wantCorrect int
+		wantTotal   int
+	}{
+		{
+			name:        "all correct",
+			questions:   []Question{{Weight: 1, Answer: Answer{Score: 1}}, {Weight: 1, Answer: Answer{Score: 1}}},
+			wantLetter:  S,
+			wantPercent: 100,
+			wantCorrect: 2,
+			wantTotal:   2,
+		},
+		{
+			name:        "partial credit",
+			questions:   []Question{{Weight: 1, Answer: Answer{Score: 1}}, {Weight: 1, Answer: Answer{Score: 0.5}}},
+			wantLetter:  B,
+			wantPercent: 75,
+			wantCorrect: 1,
+			wantTotal:   2,
+		},
+		{
+			name:        "weights",
+			questions:   []Question{{Weight: 3, Answer: Answer{Score: 1}}, {Weight: 1, Answer: Answer{Score: 0}}},
+			wantLetter:  B,
+			wantPercent: 75,
+			wantCorrect: 1,
+			wantTotal:   2,
+		},
+		{
+			name:        "zero weight is ungraded",
+			questions:   []Question{{Weight: 1, Answer: Answer{Score: 1}}, {Weight: 0, Answer: Answer{Score: 0}}},
+			wantLetter:  S,
+			wantPercent: 100,
+			wantCorrect: 1,
+			wantTotal:   1,
+		},
+		{
+			name:        "hint caps the question",
+			questions:   []Question{{Weight: 1, Answer: Answer{Score: 1, Hinted: true}}},
+			wantLetter:  B,
+			wantPercent: 85,
+			wantCorrect: 1,
+			wantTotal:   1,
+		},
+		{
+			name: "hint caps the letter at B",
+			questions: []Question{
+				{Weight: 9, Answer: Answer{Score: 1}},
+				{Weight: 1, Answer: Answer{Score: 1, Hinted: true}},
+			},
+			wantLetter:  B,
+			wantPercent: 98.5,
+			wantCorrect: 2,
+			wantTotal:   2,
+		},
+		{
+			name:        "failing",
+			questions:   []Question{{Weight: 1, Answer: Answer{Score: 0.5}}},
+			wantLetter:  F,
+			wantPercent: 50,
+			wantTotal:   1,
+		},
+		{
+			name: "no questions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := Calculate(tt.questions)
+			if g.Letter != tt.wantLetter {
+				t.Errorf("Letter = %q, want %q", g.Letter, tt.wantLetter)
+			}
+			if math.Abs(g.Percent-tt.wantPercent) > 1e-9 {
+				t.Errorf("Percent = %v, want %v", g.Percent, tt.wantPercent)
+			}
+			if g.Correct != tt.wantCorrect || g.Total != tt.wantTotal {
+				t.Errorf("Correct/Total = %d/%d, want %d/%d", g.Correct, g.Total, tt.wantCorrect, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestCalculateGrade(t *testing.T) {
+	if g := CalculateGrade(17, 20, false); g.Letter != A {
+		t.Errorf("17/20 = %s, want A", g.Letter)
+	}
+	if g := CalculateGrade(20, 20, true); g.Letter != B || !g.UsedHints {
+		t.Errorf("20/20 with hints = %s, want B", g.Letter)
+	}
+}
+
+func TestRecord_Update(t *testing.T) {
+	r := Record{}
+	if !r.Update("preface", C) {
+		t.Error("first grade should be a new best")
+	}
+	if !r.Update("preface", A) {
+		t.Error("A should beat C")
+	}
+	if r.Update("preface", B) {
+		t.Error("B should not replace A")
+	}
+	if got := r["preface"]; got != A {
+		t.Errorf("best = %s, want A", got)
+	}
+}
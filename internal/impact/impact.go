@@ -0,0 +1,99 @@
+package impact
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Root entities an impact may target
+const (
+	EntityPlayer = "player" // The player character
+	EntityNPC    = "npc"    // Non-player characters, addressed as npc.<name>
+	EntityWorld  = "world"  // Global world state
+)
+
+// impactPattern matches entity.attribute(.subattribute)*±value
+var impactPattern = regexp.MustCompile(`^([a-z_]+(?:\.[a-z_]+)+)([+-])(\d+)$`)
+
+// pathPattern matches an attribute path, entity.attribute(.subattribute)*
+var pathPattern = regexp.MustCompile(`^[a-z_]+(?:\.[a-z_]+)+$`)
+
+// Delta is a single parsed attribute change
+type Delta struct {
+	Entity    string // e.g. "player", "npc.helpful_student", "world"
+	Attribute string // e.g. "strength", "relationship"
+	Value     int    // Signed change to apply
+}
+
+// Path returns the full attribute path (entity.attribute)
+func (d Delta) Path() string {
+	return d.Entity + "." + d.Attribute
+}
+
+// String formats the delta back into impact syntax
+func (d Delta) String() string {
+	if d.Value < 0 {
+		return fmt.Sprintf("%s%d", d.Path(), d.Value)
+	}
+	return fmt.Sprintf("%s+%d", d.Path(), d.Value)
+}
+
+// Parse converts an impact string into a Delta
+// Examples: player.strength+2, npc.teacher.trust-5, world.chaos+10
+func Parse(expr string) (Delta, error) {
+	m := impactPattern.FindStringSubmatch(expr)
+	if m == nil {
+		return Delta{}, fmt.Errorf("'%s' must be format entity.attribute±value (e.g., player.strength+2)", expr)
+	}
+
+	path, sign, digits := m[1], m[2], m[3]
+	if err := ValidatePath(path); err != nil {
+		return Delta{}, fmt.Errorf("'%s': %w", expr, err)
+	}
+
+	value, err := strconv.Atoi(digits)
+	if err != nil {
+		return Delta{}, fmt.Errorf("'%s': invalid value: %w", expr, err)
+	}
+	if sign == "-" {
+		value = -value
+	}
+
+	idx := strings.LastIndex(path, ".")
+	return Delta{
+		Entity:    path[:idx],
+		Attribute: path[idx+1:],
+		Value:     value,
+	}, nil
+}
+
+// ValidatePath checks that an attribute path addresses a known entity
+// player.<attr>, world.<attr> and npc.<name>.<attr> are accepted
+func ValidatePath(path string) error {
+	parts := strings.Split(path, ".")
+	if len(parts) < 2 {
+		return fmt.Errorf("path '%s' must be entity.attribute", path)
+	}
+	for _, p := range parts {
+		if p == "" {
+			return fmt.Errorf("path '%s' has an empty segment", path)
+		}
+	}
+	if !pathPattern.MatchString(path) {
+		return fmt.Errorf("path '%s' may only contain lowercase letters and underscores between dots", path)
+	}
+
+	switch parts[0] {
+	case EntityPlayer, EntityWorld:
+		return nil
+	case EntityNPC:
+		if len(parts) < 3 {
+			return fmt.Errorf("path '%s' must name the npc (e.g., npc.teacher.trust)", path)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown entity '%s' (must be player, npc, or world)", parts[0])
+	}
+}
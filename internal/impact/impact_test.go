@@ -0,0 +1,160 @@
+package impact
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    Delta
+		wantErr bool
+	}{
+		{
+			name: "player attribute increase",
+			expr: "player.strength+2",
+			want: Delta{Entity: "player", Attribute: "strength", Value: 2},
+		},
+		{
+			name: "npc attribute decrease",
+			expr: "npc.teacher.trust-5",
+			want: Delta{Entity: "npc.teacher", Attribute: "trust", Value: -5},
+		},
+		{
+			name: "npc with underscore name",
+			expr: "npc.helpful_student.relationship+5",
+			want: Delta{Entity: "npc.helpful_student", Attribute: "relationship", Value: 5},
+		},
+		{
+			name: "world attribute",
+			expr: "world.chaos+10",
+			want: Delta{Entity: "world", Attribute: "chaos", Value: 10},
+		},
+		{
+			name:    "missing entity",
+			expr:    "strength+2",
+			wantErr: true,
+		},
+		{
+			name:    "npc without name",
+			expr:    "npc.trust+1",
+			wantErr: true,
+		},
+		{
+			name:    "unknown entity",
+			expr:    "monster.hp-3",
+			wantErr: true,
+		},
+		{
+			name:    "missing sign",
+			expr:    "player.strength2",
+			wantErr: true,
+		},
+		{
+			name:    "uppercase",
+			expr:    "Player.Strength+2",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.expr, got, tt.want)
+			}
+			if got.String() != tt.expr {
+				t.Errorf("String() = %q, want %q", got.String(), tt.expr)
+			}
+		})
+	}
+}
+
+func TestStore_Apply(t *testing.T) {
+	s := NewStore()
+
+	for _, expr := range []string{
+		"player.strength+2",
+		"player.strength+3",
+		"npc.teacher.trust-5",
+		"npc.teacher.mood+1",
+		"world.chaos+10",
+	} {
+		if _, err := s.ApplyString(expr); err != nil {
+			t.Fatalf("ApplyString(%q) error: %v", expr, err)
+		}
+	}
+
+	if got := s.Get("player.strength"); got != 5 {
+		t.Errorf("player.strength = %d, want 5", got)
+	}
+	if got := s.Get("npc.teacher.trust"); got != -5 {
+		t.Errorf("npc.teacher.trust = %d, want -5", got)
+	}
+	if got := s.Get("player.unset"); got != 0 {
+		t.Errorf("unset attribute = %d, want 0", got)
+	}
+
+	teacher := s.Entity("npc.teacher")
+	if len(teacher) != 2 || teacher["trust"] != -5 || teacher["mood"] != 1 {
+		t.Errorf("Entity(npc.teacher) = %v", teacher)
+	}
+
+	// npc itself owns no attributes, only nested entities do
+	if npc := s.Entity("npc"); len(npc) != 0 {
+		t.Errorf("Entity(npc) = %v, want empty", npc)
+	}
+
+	want := []string{"npc.teacher", "player", "world"}
+	got := s.Entities()
+	if len(got) != len(want) {
+		t.Fatalf("Entities() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Entities()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestStore_ApplyStringEmpty(t *testing.T) {
+	s := NewStore()
+	if _, err := s.ApplyString(""); err != nil {
+		t.Errorf("empty impact should be ignored, got error: %v", err)
+	}
+	if len(s) != 0 {
+		t.Errorf("empty impact should not modify store, got %v", s)
+	}
+}
+
+func TestStore_ApplyStringInvalid(t *testing.T) {
+	s := NewStore()
+	if _, err := s.ApplyString("nonsense"); err == nil {
+		t.Error("expected error for invalid impact")
+	}
+	if len(s) != 0 {
+		t.Errorf("invalid impact should not modify store, got %v", s)
+	}
+}
+
+func TestStore_Clone(t *testing.T) {
+	s := NewStore()
+	s.ApplyString("player.strength+1")
+
+	c := s.Clone()
+	c.ApplyString("player.strength+1")
+
+	if s.Get("player.strength") != 1 {
+		t.Errorf("original modified by clone: %v", s)
+	}
+	if c.Get("player.strength") != 2 {
+		t.Errorf("clone = %v, want player.strength 2", c)
+	}
+}
@@ -0,0 +1,86 @@
+package impact
+
+import (
+	"sort"
+	"strings"
+)
+
+// Store holds attribute values keyed by full path (e.g. "npc.teacher.trust")
+// Unset attributes read as zero
+type Store map[string]int
+
+// NewStore creates an empty attribute store
+func NewStore() Store {
+	return Store{}
+}
+
+// Apply adds a delta to the store
+func (s Store) Apply(d Delta) {
+	s[d.Path()] += d.Value
+}
+
+// ApplyString parses an impact string and applies it
+// Empty strings are ignored so callers can pass Choice.Impact directly
+func (s Store) ApplyString(expr string) (Delta, error) {
+	if expr == "" {
+		return Delta{}, nil
+	}
+	d, err := Parse(expr)
+	if err != nil {
+		return Delta{}, err
+	}
+	s.Apply(d)
+	return d, nil
+}
+
+// Get returns the value at a full attribute path
+func (s Store) Get(path string) int {
+	return s[path]
+}
+
+// Entity returns the attributes directly owned by an entity
+// e.g. Entity("npc.teacher") returns {"trust": 5}
+func (s Store) Entity(entity string) map[string]int {
+	prefix := entity + "."
+	attrs := make(map[string]int)
+	for path, value := range s {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		attr := path[len(prefix):]
+		if strings.Contains(attr, ".") {
+			// Belongs to a nested entity
+			continue
+		}
+		attrs[attr] = value
+	}
+	return attrs
+}
+
+// Entities returns every entity with at least one attribute, sorted
+func (s Store) Entities() []string {
+	seen := make(map[string]bool)
+	for path := range s {
+		idx := strings.LastIndex(path, ".")
+		if idx < 0 {
+			continue
+		}
+		seen[path[:idx]] = true
+	}
+
+	entities := make([]string, 0, len(seen))
+	for e := range seen {
+		entities = append(entities, e)
+	}
+	sort.Strings(entities)
+	return entities
+}
+
+// Clone returns an independent copy of the store
+func (s Store) Clone() Store {
+	c := make(Store, len(s))
+	for k, v := range s {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"sync"
+)
+
+// DefaultCacheSize is how many outputs a gateway keeps by default
+const DefaultCacheSize = 256
+
+// Cache keeps recent outputs so the same prompt never costs twice
+// The oldest entry is evicted first. A nil Cache stores nothing.
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]Output
+	order   []string // Keys, oldest first
+}
+
+// NewCache returns a cache holding up to size outputs
+func NewCache(size int) *Cache {
+	return &Cache{size: size, entries: make(map[string]Output)}
+}
+
+// CacheKey identifies a rendered prompt for a task
+func CacheKey(task Task, prompt string) string {
+	sum := sha256.Sum256([]byte(string(task) + "\x00" + prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns a copy of a cached output
+func (c *Cache) Get(key string) (Output, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out, ok := c.entries[key]
+	return maps.Clone(out), ok
+}
+
+// Put stores an output, evicting the oldest if the cache is full
+func (c *Cache) Put(key string, out Output) {
+	if c == nil || c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok {
+		if len(c.order) >= c.size {
+			delete(c.entries, c.order[0])
+			c.order = c.order[1:]
+		}
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = maps.Clone(out)
+}
+
+// Len returns the number of cached outputs
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrNotLocal is returned for model servers that are not on this machine
+var ErrNotLocal = errors.New("model server must be on localhost")
+
+// maxReplyBytes bounds how much of a server's reply is read
+const maxReplyBytes = 1 << 20
+
+// HTTPModel talks to a model server on the local machine
+// It POSTs the Request as JSON and expects {"text": ..., "tokens": ...} back.
+type HTTPModel struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPModel returns a model for the server at endpoint, which must be a
+// loopback address so the game never calls out to a hosted API.
+// A nil client uses one with a 30 second timeout. Either way, redirects
+// are only followed to loopback addresses.
+func NewHTTPModel(endpoint string, client *http.Client) (*HTTPModel, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("model server: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("model server: unsupported scheme %q", u.Scheme)
+	}
+	if !isLoopback(u.Hostname()) {
+		return nil, fmt.Errorf("%w: %s", ErrNotLocal, u.Host)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	local := *client
+	local.CheckRedirect = checkRedirect
+	return &HTTPModel{url: u.String(), client: &local}, nil
+}
+
+// checkRedirect refuses to follow a model server off this machine
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if !isLoopback(req.URL.Hostname()) {
+		return fmt.Errorf("%w: redirected to %s", ErrNotLocal, req.URL.Host)
+	}
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return nil
+}
+
+// Complete sends a request to the model server
+func (m *HTTPModel) Complete(ctx context.Context, req Request) (Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url, bytes.NewReader(body))
+	if err != nil {
+		return Response{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := m.client.Do(httpReq)
+	if err != nil {
+		return Response{}, fmt.Errorf("model server: %w", err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return Response{}, fmt.Errorf("model server: %s", httpResp.Status)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(io.LimitReader(httpResp.Body, maxReplyBytes)).Decode(&resp); err != nil {
+		return Response{}, fmt.Errorf("model server: bad reply: %w", err)
+	}
+	return resp, nil
+}
+
+// isLoopback reports whether host names this machine
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
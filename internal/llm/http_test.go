@@ -0,0 +1,111 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewHTTPModel(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		wantErr  bool
+	}{
+		{"http://localhost:8081/complete", false},
+		{"http://127.0.0.1:8081", false},
+		{"http://[::1]:8081", false},
+		{"https://api.example.com/v1/complete", true},
+		{"http://10.0.0.5:8081", true},
+		{"ftp://localhost/", true},
+		{"://bad", true},
+	}
+
+	for _, tt := range tests {
+		_, err := NewHTTPModel(tt.endpoint, nil)
+		if tt.wantErr != (err != nil) {
+			t.Errorf("NewHTTPModel(%q) error = %v, wantErr %v", tt.endpoint, err, tt.wantErr)
+		}
+	}
+
+	if _, err := NewHTTPModel("https://api.example.com", nil); !errors.Is(err, ErrNotLocal) {
+		t.Errorf("remote host error = %v, want ErrNotLocal", err)
+	}
+}
+
+func TestHTTPModel_Complete(t *testing.T) {
+	var got Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(Response{Text: `{"hint": "Count on from the larger number."}`, Tokens: 9})
+	}))
+	defer server.Close()
+
+	model, err := NewHTTPModel(server.URL, server.Client())
+	if err != nil {
+		t.Fatalf("NewHTTPModel: %v", err)
+	}
+	out, err := NewGateway(model).Run(context.Background(), TaskHint, hintVars())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if out["hint"] != "Count on from the larger number." {
+		t.Errorf("hint = %q", out["hint"])
+	}
+	if got.Task != TaskHint || got.MaxTokens == 0 || len(got.Schema.Fields) != 1 {
+		t.Errorf("server received %+v", got)
+	}
+}
+
+func TestHTTPModel_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	model, err := NewHTTPModel(server.URL, server.Client())
+	if err != nil {
+		t.Fatalf("NewHTTPModel: %v", err)
+	}
+	if _, err := model.Complete(context.Background(), Request{Task: TaskHint}); err == nil {
+		t.Error("expected error for 503")
+	}
+}
+
+func TestHTTPModel_Redirect(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		wantErr  error
+	}{
+		{"off the machine", "http://api.example.com/complete", ErrNotLocal},
+		{"on the machine", "/complete", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/complete" {
+					http.Redirect(w, r, tt.location, http.StatusTemporaryRedirect)
+					return
+				}
+				json.NewEncoder(w).Encode(Response{Text: "ok"})
+			}))
+			defer server.Close()
+
+			model, err := NewHTTPModel(server.URL, server.Client())
+			if err != nil {
+				t.Fatalf("NewHTTPModel: %v", err)
+			}
+			_, err = model.Complete(context.Background(), Request{Task: TaskHint})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Complete error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
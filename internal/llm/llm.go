@@ -0,0 +1,189 @@
+// Package llm is the only way the game talks to a language model.
+// Every call goes through a Gateway that renders a fixed prompt template,
+// filters what goes in and out, enforces token budgets, checks the reply
+// against a strict schema, and caches the result. Models are pluggable:
+// Stub answers offline and deterministically, and HTTPModel talks to a model
+// server on the local machine. No vendor API is ever called directly.
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Model generates text for a prompt
+type Model interface {
+	Complete(ctx context.Context, req Request) (Response, error)
+}
+
+// Request is a rendered prompt sent to a model
+type Request struct {
+	Task      Task   `json:"task"`
+	Prompt    string `json:"prompt"`
+	Schema    Schema `json:"schema"`     // Shape the reply must have, so models can constrain output
+	MaxTokens int    `json:"max_tokens"` // Reply budget
+}
+
+// Response is a model's raw reply
+type Response struct {
+	Text   string `json:"text"`
+	Tokens int    `json:"tokens,omitempty"` // Reply tokens as counted by the model (0 = unknown)
+}
+
+// Output is a reply that passed the schema and safety checks
+type Output map[string]string
+
+var (
+	ErrUnknownTask    = errors.New("unknown task")
+	ErrBudgetExceeded = errors.New("token budget exceeded")
+	ErrPromptTooLong  = errors.New("prompt exceeds the task's token limit")
+)
+
+// EstimateTokens approximates the tokens in s, at about four characters each
+func EstimateTokens(s string) int {
+	return (utf8.RuneCountInString(s) + 3) / 4
+}
+
+// Gateway runs tasks against a model with every constraint applied
+// It is safe for concurrent use.
+type Gateway struct {
+	model  Model
+	tasks  map[Task]TaskSpec
+	filter SafetyFilter
+	cache  *Cache
+
+	mu     sync.Mutex
+	budget int // Tokens left across all calls; negative means unlimited
+}
+
+// Option configures a Gateway
+type Option func(*Gateway)
+
+// WithTasks replaces the default task specs
+func WithTasks(tasks map[Task]TaskSpec) Option {
+	return func(g *Gateway) { g.tasks = tasks }
+}
+
+// WithFilter replaces the default safety filter
+func WithFilter(f SafetyFilter) Option {
+	return func(g *Gateway) { g.filter = f }
+}
+
+// WithCache caches outputs; nil disables caching
+func WithCache(c *Cache) Option {
+	return func(g *Gateway) { g.cache = c }
+}
+
+// WithBudget caps the tokens the gateway may spend in total
+func WithBudget(tokens int) Option {
+	return func(g *Gateway) { g.budget = tokens }
+}
+
+// NewGateway wraps a model with the default tasks, filter, and a cache
+func NewGateway(model Model, opts ...Option) *Gateway {
+	g := &Gateway{
+		model:  model,
+		tasks:  DefaultTasks(),
+		filter: DefaultFilter,
+		cache:  NewCache(DefaultCacheSize),
+		budget: -1,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Remaining returns the tokens left in the gateway's budget, or -1 if unlimited
+func (g *Gateway) Remaining() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.budget
+}
+
+// Run renders a task's prompt from vars, calls the model, and returns the
+// checked output. Vars are player or story text and are filtered first.
+func (g *Gateway) Run(ctx context.Context, task Task, vars map[string]string) (Output, error) {
+	spec, ok := g.tasks[task]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTask, task)
+	}
+
+	clean := make(map[string]string, len(vars))
+	for name, value := range vars {
+		value, err := g.filter.Input(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", task, name, err)
+		}
+		clean[name] = value
+	}
+
+	prompt, err := spec.Render(clean)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", task, err)
+	}
+	if EstimateTokens(prompt) > spec.MaxPromptTokens {
+		return nil, fmt.Errorf("%s: %w", task, ErrPromptTooLong)
+	}
+
+	key := CacheKey(task, prompt)
+	if out, ok := g.cache.Get(key); ok {
+		return out, nil
+	}
+
+	if err := g.spend(EstimateTokens(prompt) + spec.MaxReplyTokens); err != nil {
+		return nil, fmt.Errorf("%s: %w", task, err)
+	}
+
+	resp, err := g.model.Complete(ctx, Request{Task: task, Prompt: prompt, Schema: spec.Schema, MaxTokens: spec.MaxReplyTokens})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", task, err)
+	}
+	if resp.Tokens > spec.MaxReplyTokens || EstimateTokens(resp.Text) > 2*spec.MaxReplyTokens {
+		return nil, fmt.Errorf("%s: reply is over its %d token budget", task, spec.MaxReplyTokens)
+	}
+
+	out, err := spec.Schema.Parse(resp.Text)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", task, err)
+	}
+	for name, value := range out {
+		if err := g.filter.Output(value); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", task, name, err)
+		}
+	}
+
+	g.cache.Put(key, out)
+	return out, nil
+}
+
+// spend reserves tokens from the budget
+func (g *Gateway) spend(tokens int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.budget < 0 {
+		return nil
+	}
+	if tokens > g.budget {
+		return ErrBudgetExceeded
+	}
+	g.budget -= tokens
+	return nil
+}
+
+// truncateRunes shortens s to at most n runes
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// normalizeSpace collapses runs of whitespace to single spaces
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
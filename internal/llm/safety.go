@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrUnsafeInput  = errors.New("input rejected by safety filter")
+	ErrUnsafeOutput = errors.New("output rejected by safety filter")
+)
+
+// SafetyFilter screens text going to and coming from a model
+type SafetyFilter struct {
+	MaxInputLength int      // Longer inputs are truncated, in runes; 0 = unlimited
+	Blocked        []string // Terms refused in both directions, matched case-insensitively
+	AllowLinks     bool     // Let replies contain URLs
+}
+
+// DefaultFilter is used by gateways unless replaced with WithFilter
+var DefaultFilter = SafetyFilter{
+	MaxInputLength: 1000,
+}
+
+// injectionPatterns catch attempts to talk past the prompt template
+var injectionPatterns = regexp.MustCompile(`(?i)` +
+	`ignore (all |any )?(the )?(previous|prior|above) (instructions|prompts?)` +
+	`|disregard (all |any )?(the )?(previous|prior|above)` +
+	`|you are now\b` +
+	`|system prompt` +
+	`|</?(system|assistant|user)>`)
+
+// linkPattern finds URLs in replies
+var linkPattern = regexp.MustCompile(`(?i)\b(https?://|www\.)\S+`)
+
+// Input cleans text before it is placed in a prompt: control characters are
+// dropped and whitespace is collapsed. Text that tries to override the prompt
+// or contains a blocked term is refused; the rest is cut to MaxInputLength.
+func (f SafetyFilter) Input(s string) (string, error) {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+	s = normalizeSpace(s)
+
+	if injectionPatterns.MatchString(s) {
+		return "", fmt.Errorf("%w: looks like an attempt to change the instructions", ErrUnsafeInput)
+	}
+	if term, ok := f.blocked(s); ok {
+		return "", fmt.Errorf("%w: contains %q", ErrUnsafeInput, term)
+	}
+	if f.MaxInputLength > 0 {
+		s = truncateRunes(s, f.MaxInputLength)
+	}
+	return s, nil
+}
+
+// Output checks one reply field before it reaches the player
+func (f SafetyFilter) Output(s string) error {
+	if !f.AllowLinks && linkPattern.MatchString(s) {
+		return fmt.Errorf("%w: contains a link", ErrUnsafeOutput)
+	}
+	if term, ok := f.blocked(s); ok {
+		return fmt.Errorf("%w: contains %q", ErrUnsafeOutput, term)
+	}
+	return nil
+}
+
+// blocked returns the first blocked term found in s
+func (f SafetyFilter) blocked(s string) (string, bool) {
+	lower := strings.ToLower(s)
+	for _, term := range f.Blocked {
+		if term != "" && strings.Contains(lower, strings.ToLower(term)) {
+			return term, true
+		}
+	}
+	return "", false
+}
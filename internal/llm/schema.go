@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrSchema is returned when a reply does not match its task's schema
+var ErrSchema = errors.New("reply does not match schema")
+
+// Schema is the exact shape a reply must have: a flat JSON object of strings
+type Schema struct {
+	Fields []Field `json:"fields"`
+}
+
+// Field is one string field in a reply
+type Field struct {
+	Name      string   `json:"name"`
+	MaxLength int      `json:"max_length,omitempty"` // In runes; 0 = unlimited
+	Required  bool     `json:"required,omitempty"`
+	Enum      []string `json:"enum,omitempty"` // Allowed values, if any
+}
+
+// Parse checks a reply against the schema and returns its fields
+// Anything but a single JSON object with only the schema's fields is rejected.
+func (s Schema) Parse(text string) (Output, error) {
+	dec := json.NewDecoder(strings.NewReader(strings.TrimSpace(text)))
+	dec.UseNumber()
+
+	var raw map[string]any
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("%w: not a JSON object", ErrSchema)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("%w: text after the JSON object", ErrSchema)
+	}
+
+	out := make(Output, len(raw))
+	for name, value := range raw {
+		field, ok := s.field(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: unexpected field %q", ErrSchema, name)
+		}
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: field %q is not a string", ErrSchema, name)
+		}
+		str = strings.TrimSpace(str)
+		if field.MaxLength > 0 && utf8.RuneCountInString(str) > field.MaxLength {
+			return nil, fmt.Errorf("%w: field %q is longer than %d characters", ErrSchema, name, field.MaxLength)
+		}
+		if len(field.Enum) > 0 && !slices.Contains(field.Enum, str) {
+			return nil, fmt.Errorf("%w: field %q must be one of %s", ErrSchema, name, strings.Join(field.Enum, ", "))
+		}
+		out[name] = str
+	}
+
+	for _, field := range s.Fields {
+		if field.Required && out[field.Name] == "" {
+			return nil, fmt.Errorf("%w: missing field %q", ErrSchema, field.Name)
+		}
+	}
+	return out, nil
+}
+
+// field looks up a field by name
+func (s Schema) field(name string) (Field, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
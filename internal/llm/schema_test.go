@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSchema_Parse(t *testing.T) {
+	schema := Schema{Fields: []Field{
+		{Name: "reply", MaxLength: 10, Required: true},
+		{Name: "mood", Enum: []string{"warm", "cool"}},
+	}}
+
+	tests := []struct {
+		name    string
+		text    string
+		want    Output
+		wantErr bool
+	}{
+		{"valid", `{"reply": "Hello", "mood": "warm"}`, Output{"reply": "Hello", "mood": "warm"}, false},
+		{"optional omitted", ` {"reply": "Hi"} `, Output{"reply": "Hi"}, false},
+		{"trimmed", `{"reply": "  Hi  "}`, Output{"reply": "Hi"}, false},
+		{"missing required", `{"mood": "warm"}`, nil, true},
+		{"empty required", `{"reply": " "}`, nil, true},
+		{"unknown field", `{"reply": "Hi", "answer": "7"}`, nil, true},
+		{"not a string", `{"reply": 7}`, nil, true},
+		{"too long", `{"reply": "Far too long a reply"}`, nil, true},
+		{"not in enum", `{"reply": "Hi", "mood": "angry"}`, nil, true},
+		{"trailing text", `{"reply": "Hi"} and more`, nil, true},
+		{"two objects", `{"reply": "Hi"}{"reply": "Hi"}`, nil, true},
+		{"prose", `Here you go: {"reply": "Hi"}`, nil, true},
+		{"array", `["Hi"]`, nil, true},
+		{"null", `null`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schema.Parse(tt.text)
+			if tt.wantErr {
+				if !errors.Is(err, ErrSchema) {
+					t.Errorf("Parse(%q) error = %v, want ErrSchema", tt.text, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.text, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Parse(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("Parse(%q)[%q] = %q, want %q", tt.text, k, got[k], v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+)
+
+// Stub is an offline model that always gives the same reply to the same
+// request. Replies are canned per task, or built from the schema so they
+// always parse. Use it in tests and when no model server is running.
+type Stub struct {
+	Replies map[Task]string // Raw reply text per task, overriding the generated one
+	Calls   int             // Requests answered so far; not safe for concurrent use
+}
+
+// stubLines are the placeholder values for generated replies
+var stubLines = map[Task]string{
+	TaskHint:         "Read the question again slowly and look at the words it stresses.",
+	TaskReadingLevel: "The passage, unchanged; no model is running to rewrite it.",
+	TaskNPCReply:     "Hm. Ask me again once the bells have rung.",
+}
+
+// Complete answers a request without any network access
+func (s *Stub) Complete(ctx context.Context, req Request) (Response, error) {
+	if err := ctx.Err(); err != nil {
+		return Response{}, err
+	}
+	s.Calls++
+
+	if text, ok := s.Replies[req.Task]; ok {
+		return Response{Text: text, Tokens: EstimateTokens(text)}, nil
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(req.Prompt))
+	seed := h.Sum64()
+
+	line, ok := stubLines[req.Task]
+	if !ok {
+		line = "No model is running."
+	}
+	reply := make(map[string]string, len(req.Schema.Fields))
+	for _, f := range req.Schema.Fields {
+		switch {
+		case len(f.Enum) > 0:
+			reply[f.Name] = f.Enum[seed%uint64(len(f.Enum))]
+		case f.MaxLength > 0:
+			reply[f.Name] = truncateRunes(line, f.MaxLength)
+		default:
+			reply[f.Name] = line
+		}
+	}
+
+	text, err := json.Marshal(reply)
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{Text: string(text), Tokens: EstimateTokens(string(text))}, nil
+}
@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Task names a kind of model call; each has its own template and schema
+type Task string
+
+const (
+	TaskHint         Task = "hint"          // A nudge toward an answer, never the answer itself
+	TaskReadingLevel Task = "reading_level" // Rewrite scene text for a reading level
+	TaskNPCReply     Task = "npc_reply"     // A short in-character line from an NPC
+)
+
+// TaskSpec constrains one task
+type TaskSpec struct {
+	Template        *template.Template // Rendered with the task's vars; a missing var is an error
+	Schema          Schema
+	MaxPromptTokens int // Prompts longer than this are refused before calling the model
+	MaxReplyTokens  int // Reply budget passed to the model and enforced on its reply
+}
+
+// NewTaskSpec parses a prompt template for a task
+// It panics if the template is invalid, since templates are fixed at build time
+func NewTaskSpec(name, text string, schema Schema, maxPrompt, maxReply int) TaskSpec {
+	tmpl := template.Must(template.New(name).Option("missingkey=error").Parse(text))
+	return TaskSpec{Template: tmpl, Schema: schema, MaxPromptTokens: maxPrompt, MaxReplyTokens: maxReply}
+}
+
+// Render fills in the prompt template
+func (s TaskSpec) Render(vars map[string]string) (string, error) {
+	var b strings.Builder
+	if err := s.Template.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("render prompt: %w", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// replyInstructions ends every prompt so the model knows the exact output shape
+const replyInstructions = `
+Reply with a single JSON object and nothing else. {{"{"}}{{range $i, $f := .Fields}}{{if $i}}, {{end}}"{{$f}}": string{{end}}{{"}"}}`
+
+// DefaultTasks returns the built-in tasks
+func DefaultTasks() map[Task]TaskSpec {
+	hint := Schema{Fields: []Field{{Name: "hint", MaxLength: 240, Required: true}}}
+	reading := Schema{Fields: []Field{{Name: "text", MaxLength: 2000, Required: true}}}
+	npc := Schema{Fields: []Field{
+		{Name: "reply", MaxLength: 280, Required: true},
+		{Name: "mood", MaxLength: 20, Enum: []string{"warm", "neutral", "cool"}},
+	}}
+
+	return map[Task]TaskSpec{
+		TaskHint: NewTaskSpec(string(TaskHint), `
+You are a patient tutor at a school of magic, helping a student with one question.
+Give a single short hint that moves the student one step closer. Never state the answer.
+
+Question: {{.question}}
+Student's attempt: {{.attempt}}
+`+withFields(hint), hint, 600, 80),
+
+		TaskReadingLevel: NewTaskSpec(string(TaskReadingLevel), `
+Rewrite the passage for a reader at grade level {{.level}}.
+Keep every name, fact, and event. Do not add anything new.
+
+Passage:
+{{.text}}
+`+withFields(reading), reading, 1200, 600),
+
+		TaskNPCReply: NewTaskSpec(string(TaskNPCReply), `
+You are {{.npc}}, a character at a school of magic. Stay in character.
+Reply in one or two sentences to what the student just said.
+
+Character notes: {{.notes}}
+Student: {{.line}}
+`+withFields(npc), npc, 600, 100),
+	}
+}
+
+// withFields renders the reply instructions for a schema
+func withFields(s Schema) string {
+	names := make([]string, len(s.Fields))
+	for i, f := range s.Fields {
+		names[i] = f.Name
+	}
+	var b strings.Builder
+	template.Must(template.New("reply").Parse(replyInstructions)).Execute(&b, struct{ Fields []string }{names})
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MaxCookieSize is the largest cookie value browsers reliably accept
+const MaxCookieSize = 4096
+
+var (
+	// ErrInvalidCookie is returned when a cookie fails decryption or authentication
+	ErrInvalidCookie = errors.New("invalid session cookie")
+	// ErrTooLarge is returned when an encoded value exceeds MaxCookieSize
+	ErrTooLarge = errors.New("session too large for cookie")
+)
+
+// Codec encrypts and authenticates values stored in cookies using AES-GCM
+type Codec struct {
+	aead cipher.AEAD
+}
+
+// NewCodec creates a codec from a secret of any length
+// The secret is stretched to a 256-bit key with SHA-256
+func NewCodec(secret []byte) (*Codec, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("session secret must not be empty")
+	}
+
+	key := sha256.Sum256(secret)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	return &Codec{aead: aead}, nil
+}
+
+// NewRandomCodec creates a codec with a random secret
+// Cookies issued by it do not survive a server restart
+func NewRandomCodec() (*Codec, error) {
+	secret := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return NewCodec(secret)
+}
+
+// Encode serialises v as JSON, then encrypts and signs it
+// The cookie name is bound as associated data so values cannot be swapped between cookies
+func (c *Codec) Encode(name string, v any) (string, error) {
+	plaintext, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, plaintext, []byte(name))
+	encoded := base64.RawURLEncoding.EncodeToString(sealed)
+	if len(encoded) > MaxCookieSize {
+		return "", fmt.Errorf("%w (%d bytes)", ErrTooLarge, len(encoded))
+	}
+	return encoded, nil
+}
+
+// Decode verifies and decrypts a cookie value into v
+func (c *Codec) Decode(name, value string, v any) error {
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return ErrInvalidCookie
+	}
+
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return ErrInvalidCookie
+	}
+
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(name))
+	if err != nil {
+		return ErrInvalidCookie
+	}
+
+	if err := json.Unmarshal(plaintext, v); err != nil {
+		return fmt.Errorf("failed to decode session: %w", err)
+	}
+	return nil
+}
+
+// Read loads a value from the named request cookie
+// Returns http.ErrNoCookie if the cookie is absent
+func (c *Codec) Read(r *http.Request, name string, v any) error {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return err
+	}
+	return c.Decode(name, cookie.Value, v)
+}
+
+// Write stores a value in the named response cookie
+func (c *Codec) Write(w http.ResponseWriter, name string, v any) error {
+	value, err := c.Encode(name, v)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// Clear removes the named cookie
+func (c *Codec) Clear(w http.ResponseWriter, name string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
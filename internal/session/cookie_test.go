@@ -0,0 +1,120 @@
+package session
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testState struct {
+	Scene string `json:"scene"`
+	Score int    `json:"score"`
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	c, err := NewCodec([]byte("test secret"))
+	if err != nil {
+		t.Fatalf("NewCodec error: %v", err)
+	}
+
+	in := testState{Scene: "preface.0:dream-start", Score: 7}
+	encoded, err := c.Encode("game", in)
+	if err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+
+	if strings.Contains(encoded, "preface") {
+		t.Error("encoded value should not contain plaintext")
+	}
+
+	var out testState
+	if err := c.Decode("game", encoded, &out); err != nil {
+		t.Fatalf("Decode error: %v", err)
+	}
+	if out != in {
+		t.Errorf("Decode = %+v, want %+v", out, in)
+	}
+}
+
+func TestCodec_TooLarge(t *testing.T) {
+	c, err := NewCodec([]byte("test secret"))
+	if err != nil {
+		t.Fatalf("NewCodec error: %v", err)
+	}
+
+	_, err = c.Encode("game", testState{Scene: strings.Repeat("x", MaxCookieSize)})
+	if !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Encode error = %v, want ErrTooLarge", err)
+	}
+}
+
+func TestCodec_RejectsTampering(t *testing.T) {
+	c, _ := NewCodec([]byte("test secret"))
+	encoded, _ := c.Encode("game", testState{Scene: "a", Score: 1})
+
+	// Flip a character in the middle of the ciphertext
+	b := []byte(encoded)
+	mid := len(b) / 2
+	if b[mid] == 'A' {
+		b[mid] = 'B'
+	} else {
+		b[mid] = 'A'
+	}
+
+	var out testState
+	if err := c.Decode("game", string(b), &out); !errors.Is(err, ErrInvalidCookie) {
+		t.Errorf("tampered cookie error = %v, want ErrInvalidCookie", err)
+	}
+}
+
+func TestCodec_RejectsOtherKeyAndName(t *testing.T) {
+	c1, _ := NewCodec([]byte("secret one"))
+	c2, _ := NewCodec([]byte("secret two"))
+	encoded, _ := c1.Encode("game", testState{Scene: "a"})
+
+	var out testState
+	if err := c2.Decode("game", encoded, &out); !errors.Is(err, ErrInvalidCookie) {
+		t.Errorf("wrong key error = %v, want ErrInvalidCookie", err)
+	}
+	if err := c1.Decode("other", encoded, &out); !errors.Is(err, ErrInvalidCookie) {
+		t.Errorf("wrong name error = %v, want ErrInvalidCookie", err)
+	}
+	if err := c1.Decode("game", "not base64!", &out); !errors.Is(err, ErrInvalidCookie) {
+		t.Errorf("garbage error = %v, want ErrInvalidCookie", err)
+	}
+}
+
+func TestCodec_EmptySecret(t *testing.T) {
+	if _, err := NewCodec(nil); err == nil {
+		t.Error("expected error for empty secret")
+	}
+}
+
+func TestCodec_ReadWrite(t *testing.T) {
+	c, _ := NewRandomCodec()
+
+	rec := httptest.NewRecorder()
+	if err := c.Write(rec, "game", testState{Scene: "x", Score: 3}); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range rec.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+
+	var out testState
+	if err := c.Read(req, "game", &out); err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if out.Scene != "x" || out.Score != 3 {
+		t.Errorf("Read = %+v", out)
+	}
+
+	empty := httptest.NewRequest(http.MethodGet, "/", nil)
+	if err := c.Read(empty, "game", &out); !errors.Is(err, http.ErrNoCookie) {
+		t.Errorf("missing cookie error = %v, want http.ErrNoCookie", err)
+	}
+}
@@ -0,0 +1,187 @@
+package story
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jredh-dev/divine-academy/internal/condition"
+	"github.com/jredh-dev/divine-academy/internal/game"
+	"gopkg.in/yaml.v3"
+)
+
+// AbilitiesFile is the ability catalogue, kept alongside the chapter files
+const AbilitiesFile = "abilities.yaml"
+
+// YAMLAbility is one entry in the ability catalogue:
+//
+//	abilities:
+//	  - id: division
+//	    name: Division
+//	    track: mental
+//	    description: Solve division problems without hints.
+type YAMLAbility struct {
+	ID          string `yaml:"id"` // Used by ability scenes and has_ability()
+	Name        string `yaml:"name"`
+	Track       string `yaml:"track"` // mental, physical, or emotional
+	Description string `yaml:"description,omitempty"`
+}
+
+// Catalogue is the set of abilities players can unlock, in file order
+type Catalogue struct {
+	abilities []game.Ability
+	byID      map[string]int
+}
+
+// Ability looks up an ability by ID
+func (c *Catalogue) Ability(id string) (game.Ability, bool) {
+	if c == nil {
+		return game.Ability{}, false
+	}
+	i, ok := c.byID[id]
+	if !ok {
+		return game.Ability{}, false
+	}
+	return c.abilities[i], true
+}
+
+// Abilities returns every ability in file order
+func (c *Catalogue) Abilities() []game.Ability {
+	if c == nil {
+		return nil
+	}
+	return c.abilities
+}
+
+// LoadCatalogue reads and validates an ability catalogue
+// On failure the error is a Diagnostics list
+func LoadCatalogue(filename string) (*Catalogue, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, Diagnostics{{Severity: SeverityError, File: filename, Message: fmt.Sprintf("failed to read file: %v", err)}}
+	}
+
+	var file struct {
+		Abilities []yaml.Node `yaml:"abilities"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, yamlDiagnostics(filename, err)
+	}
+
+	c := &Catalogue{byID: make(map[string]int, len(file.Abilities))}
+	var diags Diagnostics
+	for i := range file.Abilities {
+		node := &file.Abilities[i]
+		fail := func(format string, args ...any) {
+			diags = append(diags, Diagnostic{
+				Severity: SeverityError,
+				File:     filename,
+				Line:     node.Line,
+				Column:   node.Column,
+				Field:    fmt.Sprintf("abilities[%d]", i),
+				Message:  fmt.Sprintf(format, args...),
+			})
+		}
+
+		var y YAMLAbility
+		if err := node.Decode(&y); err != nil {
+			diags = append(diags, yamlDiagnostics(filename, err)...)
+			continue
+		}
+
+		ability := game.Ability{
+			ID:          strings.TrimSpace(y.ID),
+			Name:        strings.TrimSpace(y.Name),
+			Description: strings.TrimSpace(y.Description),
+		}
+		switch {
+		case ability.ID == "":
+			fail("ability requires an id")
+			continue
+		case strings.ContainsAny(ability.ID, ".: "):
+			fail("id '%s' must be a simple name such as keep-your-cool", ability.ID)
+			continue
+		}
+		if _, dup := c.byID[ability.ID]; dup {
+			fail("duplicate ability id '%s'", ability.ID)
+			continue
+		}
+		if ability.Name == "" {
+			fail("ability '%s' requires a name", ability.ID)
+		}
+		track, err := game.ParseTrack(y.Track)
+		if err != nil {
+			fail("ability '%s': %v", ability.ID, err)
+		}
+		ability.Track = track
+
+		c.byID[ability.ID] = len(c.abilities)
+		c.abilities = append(c.abilities, ability)
+	}
+
+	if len(diags) > 0 {
+		return nil, diags
+	}
+	return c, nil
+}
+
+// loadCatalogueIn loads the catalogue in a scenes directory
+// A directory without one has no abilities.
+func loadCatalogueIn(dir string) (*Catalogue, error) {
+	path := filepath.Join(dir, AbilitiesFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &Catalogue{}, nil
+	}
+	return LoadCatalogue(path)
+}
+
+// resolveAbilities fills in the abilities offered by ability scenes from the
+// catalogue, and checks that every has_ability() names a catalogue entry
+func resolveAbilities(scenes []Scene, catalogue *Catalogue) Diagnostics {
+	var diags Diagnostics
+	for i := range scenes {
+		scene := &scenes[i]
+
+		for j, offered := range scene.Abilities {
+			ability, ok := catalogue.Ability(offered.ID)
+			if !ok {
+				diags = append(diags, diagnose(scene, fmt.Sprintf("abilities[%d]", j), fmt.Sprintf("unknown ability '%s' (not in %s)", offered.ID, AbilitiesFile)))
+				continue
+			}
+			scene.Abilities[j] = ability
+		}
+
+		for _, cond := range sceneConditions(scene) {
+			for _, id := range cond.expr.Abilities() {
+				if _, ok := catalogue.Ability(id); !ok {
+					diags = append(diags, diagnose(scene, cond.field, fmt.Sprintf("has_ability(%s) can never be true: '%s' is not in %s", id, id, AbilitiesFile)))
+				}
+			}
+		}
+	}
+	return diags
+}
+
+// fieldCondition is a condition and the scene field it is written in
+type fieldCondition struct {
+	field string
+	expr  *condition.Expr
+}
+
+// sceneConditions returns every condition in a scene, in field order
+func sceneConditions(scene *Scene) []fieldCondition {
+	var conds []fieldCondition
+	for i, b := range scene.Branches {
+		conds = append(conds, fieldCondition{fmt.Sprintf("next[%d].when", i), b.When})
+	}
+	for i, choice := range scene.Choices {
+		if choice.Requires != nil {
+			conds = append(conds, fieldCondition{fmt.Sprintf("choices[%d].requires", i), choice.Requires})
+		}
+		for j, b := range choice.Branches {
+			conds = append(conds, fieldCondition{fmt.Sprintf("choices[%d].next[%d].when", i, j), b.When})
+		}
+	}
+	return conds
+}
@@ -0,0 +1,194 @@
+package story
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jredh-dev/divine-academy/internal/game"
+)
+
+// testCatalogue is a small ability catalogue for scene tests
+const testCatalogue = `
+abilities:
+  - id: division
+    name: Division
+    track: mental
+    description: Solve division problems without hints.
+  - id: keep-your-cool
+    name: Keep Your Cool
+    track: emotional
+`
+
+// writeScenesWithAbilities writes scenes beside an ability catalogue
+func writeScenesWithAbilities(t *testing.T, scenes, abilities string) string {
+	t.Helper()
+	path := writeScenes(t, scenes)
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), AbilitiesFile), []byte(abilities), 0o644); err != nil {
+		t.Fatalf("Failed to write abilities: %v", err)
+	}
+	return path
+}
+
+func TestLoadCatalogue(t *testing.T) {
+	c, err := LoadCatalogue("../../scenes/abilities.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load catalogue: %v", err)
+	}
+
+	ability, ok := c.Ability("keep-your-cool")
+	if !ok {
+		t.Fatal("Expected keep-your-cool in the catalogue")
+	}
+	if ability.Name != "Keep Your Cool" || ability.Track != game.Emotional {
+		t.Errorf("keep-your-cool = %+v", ability)
+	}
+
+	tracks := map[game.KeywordCategory]int{}
+	for _, a := range c.Abilities() {
+		tracks[a.Track]++
+	}
+	for _, track := range game.Tracks {
+		if tracks[track] == 0 {
+			t.Errorf("Expected at least one %s ability", track)
+		}
+	}
+}
+
+func TestLoadCatalogueErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   string
+		wantErr string
+	}{
+		{"missing id", "name: Division\n    track: mental", "ability requires an id"},
+		{"dotted id", "id: mental.division\n    name: Division\n    track: mental", "must be a simple name"},
+		{"missing name", "id: division\n    track: mental", "ability 'division' requires a name"},
+		{"unknown track", "id: division\n    name: Division\n    track: magic", "unknown track 'magic'"},
+		{"duplicate", "id: division\n    name: Division\n    track: mental\n  - id: division\n    name: Again\n    track: mental", "duplicate ability id 'division'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), AbilitiesFile)
+			if err := os.WriteFile(path, []byte("abilities:\n  - "+tt.entry+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadCatalogue(path)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestAbilityScene(t *testing.T) {
+	path := writeScenesWithAbilities(t, `
+scenes:
+  - id: test.0:lesson
+    thread_type: affirmative
+    text: You practise long division.
+    rewards:
+      mental: 10
+    next: test.1:choose
+  - id: test.1:choose
+    thread_type: ability
+    text: Choose an ability.
+    abilities: [division, keep-your-cool]
+    next: test.2:exam
+  - id: test.2:exam
+    thread_type: multi
+    text: The exam begins.
+    choices:
+      - text: Work it out in your head
+        requires: has_ability(division)
+        next: 0
+      - text: Guess
+        next: 0
+`, testCatalogue)
+
+	scenes, err := LoadScenesFromYAML(path)
+	if err != nil {
+		t.Fatalf("Failed to load scenes: %v", err)
+	}
+
+	if got := scenes[0].Rewards[game.Mental]; got != 10 {
+		t.Errorf("Rewards[mental] = %d, want 10", got)
+	}
+
+	offered := scenes[1].Abilities
+	if len(offered) != 2 {
+		t.Fatalf("Abilities = %v, want 2", offered)
+	}
+	if offered[0].Name != "Division" || offered[0].Track != game.Mental || offered[0].Description == "" {
+		t.Errorf("Abilities[0] not resolved from the catalogue: %+v", offered[0])
+	}
+
+	// Unlocking an ability opens the gated choice
+	state := game.NewGameState("test.0:lesson")
+	state.GrantRewards("test.0:lesson", scenes[0].Rewards)
+	gated := &scenes[2].Choices[0]
+	if gated.Available(state) {
+		t.Error("Gated choice available before unlocking division")
+	}
+	if err := state.UnlockAbility(offered[0]); err != nil {
+		t.Fatalf("UnlockAbility: %v", err)
+	}
+	if !gated.Available(state) {
+		t.Error("Gated choice unavailable after unlocking division")
+	}
+}
+
+func TestAbilitySceneErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		scene   string
+		wantErr string
+	}{
+		{"no abilities", "thread_type: ability\n    next: 0", "thread_type 'ability' needs at least one ability"},
+		{"unknown ability", "thread_type: ability\n    abilities: [juggling]\n    next: 0", "abilities[0]: unknown ability 'juggling'"},
+		{"abilities elsewhere", "thread_type: affirmative\n    abilities: [division]\n    next: 0", "abilities is only supported on thread_type 'ability'"},
+		{"ability with choices", "thread_type: ability\n    abilities: [division]\n    choices:\n      - text: a\n    next: 0", "offers its abilities instead of choices"},
+		{"ability without next", "thread_type: ability\n    abilities: [division]", "thread_type 'ability' requires 'next'"},
+		{"unknown has_ability", "thread_type: multi\n    choices:\n      - text: a\n        requires: has_ability(juggling)\n        next: 0", "has_ability(juggling) can never be true"},
+		{"unknown track", "thread_type: affirmative\n    rewards:\n      magic: 5\n    next: 0", "rewards: unknown track 'magic'"},
+		{"negative reward", "thread_type: affirmative\n    rewards:\n      mental: -5\n    next: 0", "rewards.mental: reward must be a positive amount"},
+		{"choice rewards on many", "thread_type: many\n    choices:\n      - text: a\n        correct: true\n        rewards: {mental: 5}\n      - text: b\n    next: 0", "choices[0].rewards: choice rewards are only supported on thread_type 'multi'"},
+		{"unequal approach rewards", "thread_type: multi\n    choices:\n      - text: a\n        approach: empathize\n        rewards: {emotional: 5}\n        next: 0\n      - text: b\n        approach: coerce\n        rewards: {emotional: 10}\n        next: 0", "choices[1].rewards: choices with an approach must all have the same rewards"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeScenesWithAbilities(t, `
+scenes:
+  - id: test.0:scene
+    text: Scene
+    `+tt.scene+`
+`, testCatalogue)
+			_, err := LoadScenesFromYAML(path)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDiscoverFilesSkipsCatalogue(t *testing.T) {
+	dir := filepath.Dir(writeScenesWithAbilities(t, "scenes: []\n", testCatalogue))
+	files, err := SceneFiles(dir)
+	if err != nil {
+		t.Fatalf("SceneFiles: %v", err)
+	}
+	for _, f := range files {
+		if filepath.Base(f) == AbilitiesFile {
+			t.Errorf("SceneFiles included the ability catalogue: %v", files)
+		}
+	}
+}
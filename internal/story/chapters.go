@@ -0,0 +1,125 @@
+package story
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile lists chapter files in play order, relative to the scenes directory
+const ManifestFile = "chapters.yaml"
+
+// YAMLManifest represents the chapters manifest:
+//
+//	chapters:
+//	  - preface.yaml
+//	  - chapter1.yaml
+type YAMLManifest struct {
+	Chapters []string `yaml:"chapters"`
+}
+
+// LoadScenesFromDir loads every chapter in a scenes directory into one graph
+// Scenes may reference scenes in other chapters; IDs must be unique across files
+func LoadScenesFromDir(dir string) ([]Scene, error) {
+	scenes, _, err := LoadScenesFromDirWithOptions(dir, LoadOptions{})
+	return scenes, err
+}
+
+// LoadScenesFromDirWithOptions is LoadScenesFromDir with configurable graph analysis
+// In warn mode, unreachable scenes and cycles with no exit are returned as warnings
+func LoadScenesFromDirWithOptions(dir string, opts LoadOptions) ([]Scene, Diagnostics, error) {
+	files, err := SceneFiles(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil, fmt.Errorf("no scene files found in %s", dir)
+	}
+	if opts.Abilities == nil {
+		if opts.Abilities, err = loadCatalogueIn(dir); err != nil {
+			return nil, nil, err
+		}
+	}
+	return loadSceneFiles(files, opts)
+}
+
+// SceneFiles returns the scene files in a directory in chapter order
+// If the directory has a chapters.yaml manifest its order is used,
+// otherwise every .yaml/.yml file below the directory is loaded sorted by path
+func SceneFiles(dir string) ([]string, error) {
+	manifestPath := filepath.Join(dir, ManifestFile)
+	data, err := os.ReadFile(manifestPath)
+	if err == nil {
+		return manifestFiles(dir, manifestPath, data)
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return discoverFiles(dir)
+}
+
+// manifestFiles resolves the chapter list in a manifest
+func manifestFiles(dir, manifestPath string, data []byte) ([]string, error) {
+	var manifest YAMLManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse manifest: %w", manifestPath, err)
+	}
+	if len(manifest.Chapters) == 0 {
+		return nil, fmt.Errorf("%s: manifest lists no chapters", manifestPath)
+	}
+
+	files := make([]string, 0, len(manifest.Chapters))
+	seen := make(map[string]bool)
+	for i, chapter := range manifest.Chapters {
+		if !isSceneFile(chapter) {
+			return nil, fmt.Errorf("%s: chapter %d '%s' must be a .yaml file", manifestPath, i, chapter)
+		}
+		if filepath.Base(chapter) == AbilitiesFile {
+			return nil, fmt.Errorf("%s: chapter %d '%s' is the ability catalogue, not a chapter", manifestPath, i, chapter)
+		}
+
+		path := filepath.Join(dir, chapter)
+		if seen[path] {
+			return nil, fmt.Errorf("%s: chapter '%s' is listed more than once", manifestPath, chapter)
+		}
+		seen[path] = true
+
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("%s: chapter '%s': %w", manifestPath, chapter, err)
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// discoverFiles finds every scene file below a directory, sorted by path
+func discoverFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isSceneFile(path) || d.Name() == ManifestFile || d.Name() == AbilitiesFile {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// isSceneFile reports whether a path has a YAML extension
+func isSceneFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
@@ -0,0 +1,180 @@
+package story
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeChapters writes a set of files into a temporary scenes directory
+func writeChapters(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+const prefaceChapter = `scenes:
+  - id: preface.0:start
+    thread_type: affirmative
+    text: Preface
+    next: chapter1.0:class
+`
+
+const chapterOne = `scenes:
+  - id: chapter1.0:class
+    thread_type: affirmative
+    text: Chapter one
+    next: 0
+`
+
+func TestLoadScenesFromDir(t *testing.T) {
+	scenes, err := LoadScenesFromDir("../../scenes")
+	if err != nil {
+		t.Fatalf("Failed to load scenes: %v", err)
+	}
+	if scenes[0].ID != "preface.0:dream-start" {
+		t.Errorf("Expected first scene 'preface.0:dream-start', got '%s'", scenes[0].ID)
+	}
+	if !strings.HasSuffix(scenes[0].Pos.File, "preface.yaml") || scenes[0].Pos.Line == 0 {
+		t.Errorf("Expected scene position in preface.yaml, got %s", scenes[0].Pos)
+	}
+}
+
+func TestLoadScenesFromDir_Manifest(t *testing.T) {
+	dir := writeChapters(t, map[string]string{
+		ManifestFile:          "chapters:\n  - preface.yaml\n  - book1/chapter1.yaml\n",
+		"preface.yaml":        prefaceChapter,
+		"book1/chapter1.yaml": chapterOne,
+		"scratch.yaml":        "scenes: [not, listed]\n",
+	})
+
+	scenes, err := LoadScenesFromDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to load scenes: %v", err)
+	}
+	if len(scenes) != 2 {
+		t.Fatalf("Expected 2 scenes from manifest chapters, got %d", len(scenes))
+	}
+	if scenes[0].ID != "preface.0:start" || scenes[1].ID != "chapter1.0:class" {
+		t.Errorf("Expected manifest order, got %s then %s", scenes[0].ID, scenes[1].ID)
+	}
+}
+
+func TestLoadScenesFromDir_Discovery(t *testing.T) {
+	dir := writeChapters(t, map[string]string{
+		"a-preface.yml":   prefaceChapter,
+		"b-chapter1.yaml": chapterOne,
+		"notes.txt":       "ignored",
+	})
+
+	scenes, err := LoadScenesFromDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to load scenes: %v", err)
+	}
+	if len(scenes) != 2 || scenes[0].ID != "preface.0:start" {
+		t.Errorf("Expected both chapters in path order, got %v", scenes)
+	}
+}
+
+func TestLoadScenesFromDir_DiscoveryStart(t *testing.T) {
+	// Without a manifest chapter1.yaml sorts ahead of preface.yaml, so the
+	// first scene loaded is not where the story starts
+	dir := writeChapters(t, map[string]string{
+		"preface.yaml":  prefaceChapter,
+		"chapter1.yaml": chapterOne,
+	})
+
+	if _, err := LoadScenesFromDir(dir); err == nil || !strings.Contains(err.Error(), "unreachable from start scene chapter1.0:class") {
+		t.Errorf("Expected the default start to be the first file's scene, got: %v", err)
+	}
+
+	repo := NewSceneRepository(DirLoader(dir, LoadOptions{Start: "preface.0:start"}))
+	if err := repo.Reload(); err != nil {
+		t.Fatalf("Reload() with an explicit start error: %v", err)
+	}
+	if len(repo.Scenes()) != 2 {
+		t.Errorf("Expected both chapters, got %d scenes", len(repo.Scenes()))
+	}
+}
+
+func TestLoadScenesFromDir_DuplicateAcrossFiles(t *testing.T) {
+	dir := writeChapters(t, map[string]string{
+		"a.yaml": prefaceChapter + chapterOne[len("scenes:\n"):],
+		"b.yaml": chapterOne,
+	})
+
+	_, err := LoadScenesFromDir(dir)
+	if err == nil {
+		t.Fatal("Expected duplicate ID error")
+	}
+	diags := AsDiagnostics(err)
+	if len(diags) != 1 {
+		t.Fatalf("Expected one diagnostic, got %v", diags)
+	}
+	d := diags[0]
+	if filepath.Base(d.File) != "b.yaml" || d.Line != 2 || d.SceneID != "chapter1.0:class" || d.Field != "id" {
+		t.Errorf("Expected duplicate reported at b.yaml:2 id, got %+v", d)
+	}
+	if !strings.Contains(d.Message, "a.yaml:6:5") {
+		t.Errorf("Expected first definition at a.yaml:6:5, got: %s", d.Message)
+	}
+}
+
+func TestLoadScenesFromDir_BrokenCrossReference(t *testing.T) {
+	dir := writeChapters(t, map[string]string{
+		"preface.yaml": prefaceChapter,
+	})
+
+	_, err := LoadScenesFromDir(dir)
+	if err == nil {
+		t.Fatal("Expected error for missing cross-chapter scene")
+	}
+	if !strings.Contains(err.Error(), "preface.yaml:5:11: scene preface.0:start: next: 'chapter1.0:class' references non-existent scene") {
+		t.Errorf("Expected error citing file and line, got: %v", err)
+	}
+}
+
+func TestLoadScenesFromDir_ManifestErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{"missing chapter", "chapters:\n  - missing.yaml\n", "missing.yaml"},
+		{"not yaml", "chapters:\n  - notes.txt\n", "must be a .yaml file"},
+		{"listed twice", "chapters:\n  - preface.yaml\n  - preface.yaml\n", "more than once"},
+		{"empty", "chapters: []\n", "lists no chapters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeChapters(t, map[string]string{
+				ManifestFile:   tt.manifest,
+				"preface.yaml": chapterOne,
+			})
+			_, err := LoadScenesFromDir(dir)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadScenesFromDir_Empty(t *testing.T) {
+	if _, err := LoadScenesFromDir(t.TempDir()); err == nil {
+		t.Error("Expected error for directory without scene files")
+	}
+}
@@ -0,0 +1,207 @@
+package story
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity says whether a diagnostic blocks loading
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a single problem found in the scene files
+type Diagnostic struct {
+	Severity Severity  `json:"severity"`
+	File     string    `json:"file,omitempty"`
+	Line     int       `json:"line,omitempty"`
+	Column   int       `json:"column,omitempty"`
+	SceneID  string    `json:"scene,omitempty"`
+	Field    string    `json:"field,omitempty"` // e.g. "choices[1].next"
+	Kind     IssueKind `json:"kind,omitempty"`  // Set for graph analysis findings
+	Message  string    `json:"message"`
+}
+
+// String formats the diagnostic as file:line:column: scene ID: field: message
+func (d Diagnostic) String() string {
+	var parts []string
+	if d.File != "" {
+		loc := d.File
+		if d.Line > 0 {
+			loc += ":" + strconv.Itoa(d.Line)
+			if d.Column > 0 {
+				loc += ":" + strconv.Itoa(d.Column)
+			}
+		}
+		parts = append(parts, loc)
+	}
+	if d.SceneID != "" {
+		parts = append(parts, "scene "+d.SceneID)
+	}
+	if d.Field != "" {
+		parts = append(parts, d.Field)
+	}
+	parts = append(parts, d.Message)
+	return strings.Join(parts, ": ")
+}
+
+// Diagnostics is a list of problems; it is returned as the error when loading fails
+// Use errors.As to inspect individual entries
+type Diagnostics []Diagnostic
+
+// Error lists every diagnostic, one per line
+func (ds Diagnostics) Error() string {
+	lines := make([]string, len(ds))
+	for i, d := range ds {
+		lines[i] = d.String()
+	}
+	return fmt.Sprintf("scene validation failed:\n  - %s", strings.Join(lines, "\n  - "))
+}
+
+// HasErrors reports whether any diagnostic is an error
+func (ds Diagnostics) HasErrors() bool {
+	for _, d := range ds {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// AsDiagnostics extracts the diagnostics from a load error
+// Errors that are not Diagnostics become a single diagnostic without a position
+func AsDiagnostics(err error) Diagnostics {
+	if err == nil {
+		return nil
+	}
+	var ds Diagnostics
+	if errors.As(err, &ds) {
+		return ds
+	}
+	return Diagnostics{{Severity: SeverityError, Message: err.Error()}}
+}
+
+// fieldError attributes an error to a field of a scene, such as "choices[1].requires"
+type fieldError struct {
+	field string
+	err   error
+}
+
+func (e *fieldError) Error() string {
+	return e.field + ": " + e.err.Error()
+}
+
+func (e *fieldError) Unwrap() error {
+	return e.err
+}
+
+// atField wraps an error with the field it concerns
+func atField(field string, err error) error {
+	return &fieldError{field: field, err: err}
+}
+
+// diagnose creates an error diagnostic located at a field of a scene
+func diagnose(scene *Scene, field, message string) Diagnostic {
+	pos := scene.fieldPos(field)
+	return Diagnostic{
+		Severity: SeverityError,
+		File:     pos.File,
+		Line:     pos.Line,
+		Column:   pos.Column,
+		SceneID:  scene.ID,
+		Field:    field,
+		Message:  message,
+	}
+}
+
+// diagnoseErr converts an error into a diagnostic, using its field if it has one
+func diagnoseErr(scene *Scene, err error) Diagnostic {
+	var fe *fieldError
+	if errors.As(err, &fe) {
+		return diagnose(scene, fe.field, fe.err.Error())
+	}
+	return diagnose(scene, "", err.Error())
+}
+
+// Diagnostic converts a graph issue to a diagnostic with the given severity
+func (i GraphIssue) Diagnostic(severity Severity) Diagnostic {
+	return Diagnostic{
+		Severity: severity,
+		File:     i.Pos.File,
+		Line:     i.Pos.Line,
+		Column:   i.Pos.Column,
+		SceneID:  i.SceneID,
+		Kind:     i.Kind,
+		Message:  i.Message,
+	}
+}
+
+// recordFields stores the position of every field below a scene's YAML node,
+// keyed by path such as "choices[1].next" or "validation.keywords[0]"
+func recordFields(node *yaml.Node, file, path string, fields map[string]Position) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			child := key.Value
+			if path != "" {
+				child = path + "." + key.Value
+			}
+			fields[child] = Position{File: file, Line: value.Line, Column: value.Column}
+			recordFields(value, file, child, fields)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			child := fmt.Sprintf("%s[%d]", path, i)
+			fields[child] = Position{File: file, Line: item.Line, Column: item.Column}
+			recordFields(item, file, child, fields)
+		}
+	}
+}
+
+// fieldPos returns the position of a field, falling back to its nearest
+// recorded parent and finally to the scene itself
+func (s *Scene) fieldPos(field string) Position {
+	for field != "" {
+		if pos, ok := s.fields[field]; ok {
+			return pos
+		}
+		cut := strings.LastIndexAny(field, ".[")
+		if cut < 0 {
+			break
+		}
+		field = field[:cut]
+	}
+	return s.Pos
+}
+
+// yamlLinePattern finds the line number in yaml.v3 error messages
+var yamlLinePattern = regexp.MustCompile(`line (\d+): (.*)`)
+
+// yamlDiagnostics converts a yaml.v3 parse or decode error into diagnostics
+func yamlDiagnostics(file string, err error) Diagnostics {
+	messages := []string{err.Error()}
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	}
+
+	ds := make(Diagnostics, 0, len(messages))
+	for _, msg := range messages {
+		d := Diagnostic{Severity: SeverityError, File: file, Message: strings.TrimPrefix(msg, "yaml: ")}
+		if m := yamlLinePattern.FindStringSubmatch(msg); m != nil {
+			d.Line, _ = strconv.Atoi(m[1])
+			d.Message = m[2]
+		}
+		ds = append(ds, d)
+	}
+	return ds
+}
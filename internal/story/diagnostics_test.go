@@ -0,0 +1,157 @@
+package story
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDiagnostics_FieldPositions(t *testing.T) {
+	path := writeScenes(t, `scenes:
+  - id: test.0:start
+    thread_type: multi
+    text: Start
+    choices:
+      - text: Fine
+        next: "0"
+      - text: Broken
+        next: test.9:missing
+        impact: player.strength
+      - text: Gated
+        next: "0"
+        requires: visited(test.8:gone)
+  - id: Bad ID
+    thread_type: affirmative
+    text: Bad
+    next:
+      - when: player.strength >= 1
+        next: "0"
+      - next: test.7:nowhere
+`)
+
+	_, err := LoadScenesFromYAML(path)
+	if err == nil {
+		t.Fatal("Expected validation errors")
+	}
+
+	var diags Diagnostics
+	if !errors.As(err, &diags) {
+		t.Fatalf("Expected Diagnostics error, got %T", err)
+	}
+
+	want := []struct {
+		scene  string
+		field  string
+		line   int
+		column int
+		text   string
+	}{
+		{"Bad ID", "id", 14, 9, "invalid scene ID format"},
+		{"test.0:start", "choices[1].next", 9, 15, "'test.9:missing' references non-existent scene"},
+		{"test.0:start", "choices[1].impact", 10, 17, "invalid impact format"},
+		{"test.0:start", "choices[2].requires", 13, 19, "non-existent scene 'test.8:gone'"},
+	}
+
+	if len(diags) != len(want) {
+		t.Fatalf("Expected %d diagnostics, got %d:\n%v", len(want), len(diags), diags)
+	}
+	for i, w := range want {
+		d := diags[i]
+		if d.SceneID != w.scene || d.Field != w.field || d.Line != w.line || d.Column != w.column {
+			t.Errorf("diag %d = %s (%d:%d), want scene %s field %s at %d:%d", i, d, d.Line, d.Column, w.scene, w.field, w.line, w.column)
+		}
+		if !strings.Contains(d.Message, w.text) {
+			t.Errorf("diag %d message = %q, want %q", i, d.Message, w.text)
+		}
+		if d.Severity != SeverityError || d.File != path {
+			t.Errorf("diag %d = %+v, want error in %s", i, d, path)
+		}
+	}
+}
+
+func TestDiagnostics_BranchDefault(t *testing.T) {
+	path := writeScenes(t, `scenes:
+  - id: test.0:start
+    thread_type: affirmative
+    text: Start
+    next:
+      - when: player.strength >= 1
+        next: "0"
+      - next: test.7:nowhere
+`)
+
+	_, err := LoadScenesFromYAML(path)
+	diags := AsDiagnostics(err)
+	if len(diags) != 1 {
+		t.Fatalf("Expected one diagnostic, got %v", diags)
+	}
+	if diags[0].Field != "next[1].next" || diags[0].Line != 8 {
+		t.Errorf("Expected default branch reported at next[1].next line 8, got %s", diags[0])
+	}
+}
+
+func TestDiagnostics_YAMLSyntax(t *testing.T) {
+	path := writeScenes(t, "scenes:\n  - id: test.0:start\n    text: [unclosed\n")
+
+	_, err := LoadScenesFromYAML(path)
+	diags := AsDiagnostics(err)
+	if len(diags) != 1 {
+		t.Fatalf("Expected one diagnostic, got %v", diags)
+	}
+	if diags[0].File != path || diags[0].Line == 0 {
+		t.Errorf("Expected syntax error with file and line, got %+v", diags[0])
+	}
+}
+
+func TestDiagnostics_YAMLType(t *testing.T) {
+	path := writeScenes(t, `scenes:
+  - id: test.0:start
+    thread_type: affirmative
+    text: Start
+    teaches: nope
+    next: 0
+`)
+
+	_, err := LoadScenesFromYAML(path)
+	diags := AsDiagnostics(err)
+	if len(diags) != 1 || diags[0].Line != 5 {
+		t.Errorf("Expected type error on line 5, got %v", diags)
+	}
+}
+
+func TestDiagnostic_String(t *testing.T) {
+	tests := []struct {
+		d    Diagnostic
+		want string
+	}{
+		{
+			Diagnostic{File: "a.yaml", Line: 3, Column: 5, SceneID: "x.0:y", Field: "next", Message: "bad"},
+			"a.yaml:3:5: scene x.0:y: next: bad",
+		},
+		{
+			Diagnostic{File: "a.yaml", Line: 3, Message: "bad"},
+			"a.yaml:3: bad",
+		},
+		{
+			Diagnostic{Message: "bad"},
+			"bad",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.d.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestAsDiagnostics(t *testing.T) {
+	if AsDiagnostics(nil) != nil {
+		t.Error("Expected nil for nil error")
+	}
+
+	plain := AsDiagnostics(errors.New("boom"))
+	if len(plain) != 1 || plain[0].Message != "boom" || plain[0].Severity != SeverityError {
+		t.Errorf("Expected plain error wrapped, got %v", plain)
+	}
+}
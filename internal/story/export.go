@@ -0,0 +1,226 @@
+package story
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxEdgeLabel caps edge label length so large graphs stay readable
+const maxEdgeLabel = 40
+
+// threadStyle describes how a thread type is drawn
+type threadStyle struct {
+	dotShape  string
+	fill      string
+	mermaidOp string // Opening bracket of the Mermaid node shape
+	mermaidCl string // Closing bracket
+}
+
+// threadStyles maps each thread type to its node style
+var threadStyles = map[ThreadType]threadStyle{
+	ThreadMulti:       {dotShape: "diamond", fill: "#dbe4ff", mermaidOp: "{", mermaidCl: "}"},
+	ThreadMany:        {dotShape: "hexagon", fill: "#e5dbff", mermaidOp: "{{", mermaidCl: "}}"},
+	ThreadOpen:        {dotShape: "parallelogram", fill: "#d3f9d8", mermaidOp: "[/", mermaidCl: "/]"},
+	ThreadAffirmative: {dotShape: "box", fill: "#f1f3f5", mermaidOp: "[", mermaidCl: "]"},
+	ThreadFinisher:    {dotShape: "component", fill: "#fff3bf", mermaidOp: "[[", mermaidCl: "]]"},
+	ThreadAbility:     {dotShape: "octagon", fill: "#ffe3e3", mermaidOp: "([", mermaidCl: "])"},
+}
+
+// graphEdge is one arrow in an exported graph
+type graphEdge struct {
+	from, to string
+	label    string
+}
+
+// graphEdges lists every edge in the scene graph with its label
+// Choices are labelled with their text and impact, branches with their condition
+func graphEdges(scenes []Scene) []graphEdge {
+	var edges []graphEdge
+	for i := range scenes {
+		scene := &scenes[i]
+		if scene.ThreadType == ThreadMulti {
+			for _, choice := range scene.Choices {
+				label := choice.Text
+				if choice.Impact != "" {
+					label = fmt.Sprintf("%s (%s)", truncate(label), choice.Impact)
+				}
+				if choice.Requires != nil {
+					label = fmt.Sprintf("%s [if %s]", truncate(label), choice.Requires)
+				}
+				edges = append(edges, routeEdges(scene.ID, label, choice.Branches, choice.Next)...)
+			}
+			continue
+		}
+		edges = append(edges, routeEdges(scene.ID, "", scene.Branches, scene.Next)...)
+	}
+	return edges
+}
+
+// routeEdges creates the edges for a 'next' field and its branches
+func routeEdges(from, label string, branches []Branch, def string) []graphEdge {
+	var edges []graphEdge
+	for _, b := range branches {
+		edges = append(edges, graphEdge{from: from, to: b.Next, label: joinLabel(label, "when "+b.When.String())})
+	}
+	if len(branches) > 0 {
+		label = joinLabel(label, "otherwise")
+	}
+	return append(edges, graphEdge{from: from, to: def, label: label})
+}
+
+// joinLabel combines a choice label with a branch description
+func joinLabel(label, branch string) string {
+	if label == "" {
+		return branch
+	}
+	return truncate(label) + " / " + branch
+}
+
+// truncate shortens a label to maxEdgeLabel runes
+func truncate(s string) string {
+	runes := []rune(s)
+	if len(runes) <= maxEdgeLabel {
+		return s
+	}
+	return string(runes[:maxEdgeLabel-1]) + "…"
+}
+
+// sinkLabel names a terminal or error target
+func sinkLabel(id string) string {
+	if id == "0" {
+		return "END"
+	}
+	return "ERROR " + id
+}
+
+// sinks returns the distinct exit targets in order of first use
+func sinks(edges []graphEdge) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, e := range edges {
+		if isExit(e.to) && !seen[e.to] {
+			seen[e.to] = true
+			ids = append(ids, e.to)
+		}
+	}
+	return ids
+}
+
+// WriteDOT writes the scene graph as a Graphviz digraph
+// Render with: dot -Tsvg story.dot -o story.svg
+func WriteDOT(w io.Writer, scenes []Scene) error {
+	bw := bufio.NewWriter(w)
+	edges := graphEdges(scenes)
+
+	dotID := func(id string) string {
+		if isExit(id) {
+			return dotQuote("__exit" + id)
+		}
+		return dotQuote(id)
+	}
+
+	fmt.Fprintln(bw, "digraph story {")
+	fmt.Fprintln(bw, `  rankdir=TB;`)
+	fmt.Fprintln(bw, `  node [fontname="Helvetica", style=filled];`)
+	fmt.Fprintln(bw, `  edge [fontname="Helvetica", fontsize=10];`)
+	fmt.Fprintln(bw)
+
+	for i := range scenes {
+		style := threadStyles[scenes[i].ThreadType]
+		fmt.Fprintf(bw, "  %s [label=%s, shape=%s, fillcolor=%s];\n",
+			dotID(scenes[i].ID), dotQuote(scenes[i].ID), style.dotShape, dotQuote(style.fill))
+	}
+	for _, id := range sinks(edges) {
+		fill := "#b2f2bb"
+		if id != "0" {
+			fill = "#ffc9c9"
+		}
+		fmt.Fprintf(bw, "  %s [label=%s, shape=doublecircle, fillcolor=%s];\n", dotID(id), dotQuote(sinkLabel(id)), dotQuote(fill))
+	}
+	fmt.Fprintln(bw)
+
+	for _, e := range edges {
+		if e.label == "" {
+			fmt.Fprintf(bw, "  %s -> %s;\n", dotID(e.from), dotID(e.to))
+			continue
+		}
+		fmt.Fprintf(bw, "  %s -> %s [label=%s];\n", dotID(e.from), dotID(e.to), dotQuote(e.label))
+	}
+
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// dotQuote quotes a string for use as a DOT ID or attribute
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// WriteMermaid writes the scene graph as a Mermaid flowchart
+// Paste the output into a ```mermaid block in Markdown to render it
+func WriteMermaid(w io.Writer, scenes []Scene) error {
+	bw := bufio.NewWriter(w)
+	edges := graphEdges(scenes)
+
+	// Scene IDs contain characters Mermaid does not allow in node IDs
+	nodeIDs := make(map[string]string)
+	for i := range scenes {
+		if _, exists := nodeIDs[scenes[i].ID]; !exists {
+			nodeIDs[scenes[i].ID] = fmt.Sprintf("s%d", i)
+		}
+	}
+	nodeID := func(id string) string {
+		if id == "0" {
+			return "exit_end"
+		}
+		if isExit(id) {
+			return "exit_err" + strings.TrimPrefix(id, "-")
+		}
+		return nodeIDs[id]
+	}
+
+	fmt.Fprintln(bw, "flowchart TD")
+	for i := range scenes {
+		style := threadStyles[scenes[i].ThreadType]
+		fmt.Fprintf(bw, "    %s%s%s%s:::%s\n",
+			nodeID(scenes[i].ID), style.mermaidOp, mermaidQuote(scenes[i].ID), style.mermaidCl, scenes[i].ThreadType)
+	}
+	for _, id := range sinks(edges) {
+		class := "exitEnd"
+		if id != "0" {
+			class = "exitError"
+		}
+		fmt.Fprintf(bw, "    %s((%s)):::%s\n", nodeID(id), mermaidQuote(sinkLabel(id)), class)
+	}
+
+	for _, e := range edges {
+		if e.label == "" {
+			fmt.Fprintf(bw, "    %s --> %s\n", nodeID(e.from), nodeID(e.to))
+			continue
+		}
+		fmt.Fprintf(bw, "    %s -->|%s| %s\n", nodeID(e.from), mermaidQuote(e.label), nodeID(e.to))
+	}
+
+	for _, t := range []ThreadType{ThreadMulti, ThreadMany, ThreadOpen, ThreadAffirmative, ThreadFinisher, ThreadAbility} {
+		fmt.Fprintf(bw, "    classDef %s fill:%s\n", t, threadStyles[t].fill)
+	}
+	fmt.Fprintln(bw, "    classDef exitEnd fill:#b2f2bb")
+	fmt.Fprintln(bw, "    classDef exitError fill:#ffc9c9")
+
+	return bw.Flush()
+}
+
+// mermaidQuote quotes a label for Mermaid, escaping characters that break its syntax
+func mermaidQuote(s string) string {
+	r := strings.NewReplacer(
+		`"`, "#quot;",
+		"|", "#124;",
+		"\n", " ",
+	)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,117 @@
+package story
+
+import (
+	"strings"
+	"testing"
+)
+
+// exportScenes covers choice impacts, gates, branches, and both kinds of sink
+const exportScenes = `
+scenes:
+  - id: test.0:start
+    thread_type: multi
+    text: Start
+    choices:
+      - text: Say "hello"
+        impact: player.empathy+1
+        next: test.1:check
+      - text: Secret door
+        requires: player.empathy >= 1
+        next: "-1"
+  - id: test.1:check
+    thread_type: open
+    text: Check
+    next:
+      - when: player.empathy > 0
+        next: test.2:done
+      - next: test.3:finish
+  - id: test.2:done
+    thread_type: affirmative
+    text: Done
+    next: 0
+  - id: test.3:finish
+    thread_type: finisher
+    text: Finish
+    target: The end
+    next: 0
+`
+
+func loadExportScenes(t *testing.T) []Scene {
+	t.Helper()
+	scenes, _, err := loadSceneFiles([]string{writeScenes(t, exportScenes)}, LoadOptions{Mode: ModeWarn})
+	if err != nil {
+		t.Fatalf("Failed to load scenes: %v", err)
+	}
+	return scenes
+}
+
+func TestWriteDOT(t *testing.T) {
+	var b strings.Builder
+	if err := WriteDOT(&b, loadExportScenes(t)); err != nil {
+		t.Fatalf("WriteDOT() error: %v", err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"digraph story {",
+		`"test.0:start" [label="test.0:start", shape=diamond`,
+		`"test.1:check" [label="test.1:check", shape=parallelogram`,
+		`"test.3:finish" [label="test.3:finish", shape=component`,
+		`"__exit0" [label="END", shape=doublecircle`,
+		`"__exit-1" [label="ERROR -1", shape=doublecircle`,
+		`"test.0:start" -> "test.1:check" [label="Say \"hello\" (player.empathy+1)"];`,
+		`"test.0:start" -> "__exit-1" [label="Secret door [if player.empathy >= 1]"];`,
+		`"test.1:check" -> "test.2:done" [label="when player.empathy > 0"];`,
+		`"test.1:check" -> "test.3:finish" [label="otherwise"];`,
+		`"test.2:done" -> "__exit0";`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT output missing %q\n%s", want, out)
+		}
+	}
+
+	// Each sink is declared once however many edges reach it
+	if n := strings.Count(out, `"__exit0" [`); n != 1 {
+		t.Errorf("END node declared %d times, want 1", n)
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var b strings.Builder
+	if err := WriteMermaid(&b, loadExportScenes(t)); err != nil {
+		t.Fatalf("WriteMermaid() error: %v", err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"flowchart TD",
+		`s0{"test.0:start"}:::multi`,
+		`s1[/"test.1:check"/]:::open`,
+		`s2["test.2:done"]:::affirmative`,
+		`s3[["test.3:finish"]]:::finisher`,
+		`exit_end(("END")):::exitEnd`,
+		`exit_err1(("ERROR -1")):::exitError`,
+		`s0 -->|"Say #quot;hello#quot; (player.empathy+1)"| s1`,
+		`s1 -->|"when player.empathy > 0"| s2`,
+		`s2 --> exit_end`,
+		"classDef exitError",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Mermaid output missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	long := strings.Repeat("a", maxEdgeLabel+10)
+	got := truncate(long)
+	if n := len([]rune(got)); n != maxEdgeLabel {
+		t.Errorf("truncate() length = %d, want %d", n, maxEdgeLabel)
+	}
+	if !strings.HasSuffix(got, "…") {
+		t.Errorf("truncate() = %q, want ellipsis", got)
+	}
+	if got := truncate("short"); got != "short" {
+		t.Errorf("truncate(short) = %q, want unchanged", got)
+	}
+}
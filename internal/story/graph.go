@@ -0,0 +1,271 @@
+package story
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidationMode controls how graph analysis findings are reported
+type ValidationMode int
+
+const (
+	ModeStrict ValidationMode = iota // Findings fail the load
+	ModeWarn                         // Findings are returned as warnings
+)
+
+// String returns the mode name used on the command line
+func (m ValidationMode) String() string {
+	if m == ModeWarn {
+		return "warn"
+	}
+	return "strict"
+}
+
+// ParseValidationMode converts "strict" or "warn" to a ValidationMode
+func ParseValidationMode(s string) (ValidationMode, error) {
+	switch s {
+	case "strict":
+		return ModeStrict, nil
+	case "warn":
+		return ModeWarn, nil
+	}
+	return ModeStrict, fmt.Errorf("invalid validation mode '%s' (must be strict or warn)", s)
+}
+
+// LoadOptions configures scene loading and graph analysis
+type LoadOptions struct {
+	Mode  ValidationMode
+	Start string // Entry scene for reachability; defaults to the first scene loaded
+
+	// Abilities scenes may offer and conditions may test
+	// Loaded from the scenes directory's abilities.yaml when nil
+	Abilities *Catalogue
+}
+
+// IssueKind categorises a graph analysis finding
+type IssueKind string
+
+const (
+	IssueUnreachable IssueKind = "unreachable" // Scene cannot be reached from the start
+	IssueNoExit      IssueKind = "no-exit"     // Cycle that never reaches an ending
+	IssueDeadEnd     IssueKind = "dead-end"    // Scene whose every path leads into a no-exit cycle
+)
+
+// GraphIssue is a structural problem found by AnalyzeGraph
+type GraphIssue struct {
+	Kind    IssueKind
+	SceneID string
+	Pos     Position
+	Message string
+}
+
+// String formats the issue like a validation error
+func (i GraphIssue) String() string {
+	if i.Pos.File == "" {
+		return fmt.Sprintf("scene %s: %s", i.SceneID, i.Message)
+	}
+	return fmt.Sprintf("%s: scene %s: %s", i.Pos, i.SceneID, i.Message)
+}
+
+// isExit reports whether a 'next' value ends the story ("0") or enters an error state ("-N")
+func isExit(next string) bool {
+	return next == "0" || strings.HasPrefix(next, "-")
+}
+
+// Edges returns the scenes a scene can lead to, ignoring conditions
+// Multi scenes follow their choices; other thread types use the scene-level next
+func (s *Scene) Edges() []string {
+	if s.ThreadType != ThreadMulti {
+		return s.Targets()
+	}
+	var edges []string
+	for i := range s.Choices {
+		edges = append(edges, s.Choices[i].Targets()...)
+	}
+	return edges
+}
+
+// AnalyzeGraph finds scenes unreachable from the start and scenes that can
+// never reach an ending. Conditions are ignored, so every branch counts as taken.
+// The scenes are assumed to have passed validateSceneGraph.
+func AnalyzeGraph(scenes []Scene, start string) []GraphIssue {
+	if len(scenes) == 0 {
+		return nil
+	}
+	if start == "" {
+		start = scenes[0].ID
+	}
+
+	sceneMap := make(map[string]*Scene, len(scenes))
+	for i := range scenes {
+		if _, exists := sceneMap[scenes[i].ID]; !exists {
+			sceneMap[scenes[i].ID] = &scenes[i]
+		}
+	}
+
+	var issues []GraphIssue
+
+	// Forward search from the start scene
+	reachable := make(map[string]bool)
+	queue := []string{start}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		scene, exists := sceneMap[id]
+		if !exists || reachable[id] {
+			continue
+		}
+		reachable[id] = true
+		queue = append(queue, scene.Edges()...)
+	}
+
+	for i := range scenes {
+		if !reachable[scenes[i].ID] {
+			issues = append(issues, newIssue(&scenes[i], IssueUnreachable,
+				fmt.Sprintf("unreachable from start scene %s", start)))
+		}
+	}
+
+	// Backward search from every scene with an edge to an ending
+	incoming := make(map[string][]string)
+	exiting := make(map[string]bool)
+	queue = queue[:0]
+	for i := range scenes {
+		for _, next := range scenes[i].Edges() {
+			if isExit(next) {
+				queue = append(queue, scenes[i].ID)
+			} else {
+				incoming[next] = append(incoming[next], scenes[i].ID)
+			}
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if exiting[id] {
+			continue
+		}
+		exiting[id] = true
+		queue = append(queue, incoming[id]...)
+	}
+
+	// Scenes that cannot exit either sit in a closed cycle or lead into one
+	trapped := make(map[string]bool)
+	for _, cycle := range closedCycles(scenes, sceneMap, exiting) {
+		for _, id := range cycle {
+			trapped[id] = true
+		}
+		issues = append(issues, newIssue(sceneMap[cycle[0]], IssueNoExit,
+			fmt.Sprintf("cycle with no exit: %s", strings.Join(append(cycle, cycle[0]), " -> "))))
+	}
+	for i := range scenes {
+		id := scenes[i].ID
+		if !exiting[id] && !trapped[id] {
+			issues = append(issues, newIssue(&scenes[i], IssueDeadEnd, "every path leads into a cycle with no exit"))
+		}
+	}
+
+	return issues
+}
+
+// closedCycles returns the strongly connected components of non-exiting
+// scenes that have no edges out of the component, each ordered by load order
+func closedCycles(scenes []Scene, sceneMap map[string]*Scene, exiting map[string]bool) [][]string {
+	order := make(map[string]int, len(scenes))
+	for i := range scenes {
+		if _, exists := order[scenes[i].ID]; !exists {
+			order[scenes[i].ID] = i
+		}
+	}
+
+	// Tarjan's algorithm over scenes that cannot reach an ending
+	index := 0
+	indices := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+
+	var connect func(id string)
+	connect = func(id string) {
+		indices[id] = index
+		lowlink[id] = index
+		index++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, next := range sceneMap[id].Edges() {
+			if _, exists := sceneMap[next]; !exists || exiting[next] {
+				continue
+			}
+			if _, visited := indices[next]; !visited {
+				connect(next)
+				lowlink[id] = min(lowlink[id], lowlink[next])
+			} else if onStack[next] {
+				lowlink[id] = min(lowlink[id], indices[next])
+			}
+		}
+
+		if lowlink[id] == indices[id] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == id {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+
+	for i := range scenes {
+		id := scenes[i].ID
+		if _, visited := indices[id]; !visited && !exiting[id] {
+			connect(id)
+		}
+	}
+
+	var closed [][]string
+	for _, component := range components {
+		members := make(map[string]bool, len(component))
+		for _, id := range component {
+			members[id] = true
+		}
+
+		leaves := false
+		for _, id := range component {
+			for _, next := range sceneMap[id].Edges() {
+				if !members[next] {
+					leaves = true
+				}
+			}
+		}
+		if leaves {
+			continue
+		}
+
+		sort.Slice(component, func(i, j int) bool {
+			return order[component[i]] < order[component[j]]
+		})
+		closed = append(closed, component)
+	}
+
+	sort.Slice(closed, func(i, j int) bool {
+		return order[closed[i][0]] < order[closed[j][0]]
+	})
+	return closed
+}
+
+// newIssue creates an issue located at a scene
+func newIssue(scene *Scene, kind IssueKind, message string) GraphIssue {
+	return GraphIssue{
+		Kind:    kind,
+		SceneID: scene.ID,
+		Pos:     scene.Pos,
+		Message: message,
+	}
+}
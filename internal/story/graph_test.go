@@ -0,0 +1,157 @@
+package story
+
+import (
+	"strings"
+	"testing"
+)
+
+// graphScenes has an orphan, a closed cycle, and a scene that leads into it
+const graphScenes = `
+scenes:
+  - id: test.0:start
+    thread_type: multi
+    text: Start
+    choices:
+      - text: End
+        next: "0"
+      - text: Trap
+        next: test.2:lead-in
+  - id: test.1:orphan
+    thread_type: affirmative
+    text: Nobody links here
+    next: 0
+  - id: test.2:lead-in
+    thread_type: affirmative
+    text: Leads into the loop
+    next: test.3:loop-a
+  - id: test.3:loop-a
+    thread_type: affirmative
+    text: Loop A
+    next: test.4:loop-b
+  - id: test.4:loop-b
+    thread_type: multi
+    text: Loop B
+    choices:
+      - text: Back
+        next: test.3:loop-a
+      - text: Stay
+        next: test.4:loop-b
+`
+
+func TestAnalyzeGraph_Preface(t *testing.T) {
+	scenes, err := LoadScenesFromYAML("../../scenes/preface.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load scenes: %v", err)
+	}
+	if issues := AnalyzeGraph(scenes, ""); len(issues) != 0 {
+		t.Errorf("Expected preface to have no graph issues, got %v", issues)
+	}
+}
+
+func TestAnalyzeGraph(t *testing.T) {
+	path := writeScenes(t, graphScenes)
+
+	// Strict mode (the default) fails the load
+	_, err := LoadScenesFromYAML(path)
+	if err == nil {
+		t.Fatal("Expected strict mode to reject the graph")
+	}
+	if !strings.Contains(err.Error(), "scene test.1:orphan: unreachable") {
+		t.Errorf("Expected unreachable error with position, got: %v", err)
+	}
+
+	// Warn mode returns the findings
+	scenes, issues, err := loadSceneFiles([]string{path}, LoadOptions{Mode: ModeWarn})
+	if err != nil {
+		t.Fatalf("Expected warn mode to load, got: %v", err)
+	}
+	if len(scenes) != 5 {
+		t.Errorf("Expected 5 scenes, got %d", len(scenes))
+	}
+
+	byKind := map[IssueKind][]Diagnostic{}
+	for _, issue := range issues {
+		byKind[issue.Kind] = append(byKind[issue.Kind], issue)
+	}
+
+	if got := byKind[IssueUnreachable]; len(got) != 1 || got[0].SceneID != "test.1:orphan" {
+		t.Errorf("Expected test.1:orphan unreachable, got %v", got)
+	}
+
+	noExit := byKind[IssueNoExit]
+	if len(noExit) != 1 {
+		t.Fatalf("Expected one cycle with no exit, got %v", noExit)
+	}
+	if !strings.Contains(noExit[0].Message, "test.3:loop-a -> test.4:loop-b -> test.3:loop-a") {
+		t.Errorf("Unexpected cycle message: %s", noExit[0].Message)
+	}
+	if noExit[0].Line == 0 || noExit[0].Severity != SeverityWarning {
+		t.Errorf("Expected cycle warning with a source position, got %+v", noExit[0])
+	}
+
+	if got := byKind[IssueDeadEnd]; len(got) != 1 || got[0].SceneID != "test.2:lead-in" {
+		t.Errorf("Expected test.2:lead-in dead end, got %v", got)
+	}
+}
+
+func TestAnalyzeGraph_SelfLoop(t *testing.T) {
+	scenes := []Scene{
+		{ID: "a.0:start", ThreadType: ThreadAffirmative, Next: "a.0:start"},
+	}
+	issues := AnalyzeGraph(scenes, "")
+	if len(issues) != 1 || issues[0].Kind != IssueNoExit {
+		t.Errorf("Expected self loop to be a cycle with no exit, got %v", issues)
+	}
+}
+
+func TestAnalyzeGraph_BranchesCountAsExits(t *testing.T) {
+	// The loop can be left through a conditional branch, so it is not a trap
+	path := writeScenes(t, `
+scenes:
+  - id: test.0:loop
+    thread_type: affirmative
+    text: Loop
+    next:
+      - when: player.strength >= 5
+        next: "0"
+      - next: test.0:loop
+`)
+	if _, err := LoadScenesFromYAML(path); err != nil {
+		t.Errorf("Expected loop with conditional exit to pass, got: %v", err)
+	}
+}
+
+func TestAnalyzeGraph_StartOption(t *testing.T) {
+	path := writeScenes(t, graphScenes)
+
+	_, _, err := loadSceneFiles([]string{path}, LoadOptions{Mode: ModeWarn, Start: "test.9:missing"})
+	if err == nil || !strings.Contains(err.Error(), "start scene") {
+		t.Errorf("Expected missing start scene error, got: %v", err)
+	}
+
+	_, issues, err := loadSceneFiles([]string{path}, LoadOptions{Mode: ModeWarn, Start: "test.1:orphan"})
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	unreachable := 0
+	for _, issue := range issues {
+		if issue.Kind == IssueUnreachable {
+			unreachable++
+		}
+	}
+	if unreachable != 4 {
+		t.Errorf("Expected 4 scenes unreachable from the orphan, got %d", unreachable)
+	}
+}
+
+func TestParseValidationMode(t *testing.T) {
+	for _, mode := range []ValidationMode{ModeStrict, ModeWarn} {
+		got, err := ParseValidationMode(mode.String())
+		if err != nil || got != mode {
+			t.Errorf("ParseValidationMode(%q) = %v, %v", mode.String(), got, err)
+		}
+	}
+	if _, err := ParseValidationMode("lenient"); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+}
@@ -0,0 +1,98 @@
+package story
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+const repoScene = `
+scenes:
+  - id: test.0:start
+    thread_type: affirmative
+    text: Start
+    next: 0
+`
+
+func TestSceneRepository_Reload(t *testing.T) {
+	dir := writeChapters(t, map[string]string{"test.yaml": repoScene})
+	repo := NewSceneRepository(DirLoader(dir, LoadOptions{}))
+
+	if len(repo.Scenes()) != 0 {
+		t.Fatal("Expected a new repository to be empty until reloaded")
+	}
+	if err := repo.Reload(); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	if repo.Scene("test.0:start") == nil {
+		t.Fatal("Expected reloaded scene to be available")
+	}
+
+	// An invalid edit is rejected and the previous graph stays in place
+	bad := repoScene + "    choices: [oops\n"
+	if err := os.WriteFile(filepath.Join(dir, "test.yaml"), []byte(bad), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Reload(); err == nil {
+		t.Fatal("Expected Reload() to reject invalid YAML")
+	}
+	if repo.Scene("test.0:start") == nil {
+		t.Error("Expected previous scenes to survive a failed reload")
+	}
+}
+
+func TestSceneRepository_Abilities(t *testing.T) {
+	dir := writeChapters(t, map[string]string{
+		"test.yaml":   repoScene,
+		AbilitiesFile: "abilities:\n  - id: division\n    name: Division\n    track: mental\n",
+	})
+	repo := NewSceneRepository(DirLoader(dir, LoadOptions{}))
+	if err := repo.Reload(); err != nil {
+		t.Fatalf("Reload() error: %v", err)
+	}
+	if ability, ok := repo.Abilities().Ability("division"); !ok || ability.Name != "Division" {
+		t.Errorf("Ability(division) = %+v, %v, want the catalogue entry", ability, ok)
+	}
+}
+
+func TestSceneRepository_LoaderError(t *testing.T) {
+	want := errors.New("boom")
+	repo := NewSceneRepository(func() ([]Scene, *Catalogue, error) { return nil, nil, want })
+	if err := repo.Reload(); !errors.Is(err, want) {
+		t.Errorf("Reload() error = %v, want %v", err, want)
+	}
+}
+
+func TestSceneRepository_ConcurrentReload(t *testing.T) {
+	first := []Scene{{ID: "a.0:one", ThreadType: ThreadAffirmative, Next: "0"}}
+	second := []Scene{{ID: "a.0:one", ThreadType: ThreadAffirmative, Next: "0"}, {ID: "a.1:two"}}
+	repo := NewStaticRepository(first, nil)
+
+	// Run with -race: readers must never observe a half-swapped graph
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if repo.Scene("a.0:one") == nil {
+					t.Error("Scene disappeared during reload")
+					return
+				}
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if (i+j)%2 == 0 {
+					repo.Replace(first, nil)
+				} else {
+					repo.Replace(second, nil)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -1,6 +1,26 @@
 package story
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/jredh-dev/divine-academy/internal/condition"
+	"github.com/jredh-dev/divine-academy/internal/game"
+)
+
+// Position is a location in a scene source file
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// String formats the position as file:line:column
+func (p Position) String() string {
+	if p.File == "" {
+		return "<unknown>"
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
 
 // Scene represents a single story beat
 type Scene struct {
@@ -8,69 +28,106 @@ type Scene struct {
 	ThreadType ThreadType
 	Text       string
 	Choices    []Choice
-	Next       string // For open/affirmative/finisher thread types
-	MinLength  int    // For open responses
+	Next       string                        // For open/affirmative/finisher/many thread types (default when branching)
+	Branches   []Branch                      // Conditional routes checked in order before Next
+	MinLength  int                           // For open responses
+	Validator  game.Validator                // Checks open responses (nil = any text of MinLength)
+	Finisher   *game.FinisherValidator       // Target passage for finisher scenes
+	Selection  *game.MultipleChoiceValidator // Scores many-choice selections
+	Teaches    []string                      // Concepts the player learns on entering this scene
+	Weight     float64                       // Importance in the chapter grade (0 for ungraded scenes)
+	Hints      []string                      // Genie hints in tiers, from gentle to revealing
+	Rewards    game.Rewards                  // Experience granted on completing the scene
+	Abilities  []game.Ability                // Abilities offered on an ability scene
+	Pos        Position                      // Where the scene is defined
+
+	fields map[string]Position // Source positions of individual fields, for diagnostics
 }
 
 // Choice represents an option the player can select
 type Choice struct {
-	Text   string
-	Next   string // Scene ID to transition to
-	Impact string // Format: "entity.attribute±value" (stored, not processed yet)
+	Text     string
+	Next     string          // Scene ID to transition to (default when branching)
+	Branches []Branch        // Conditional routes checked in order before Next
+	Impact   string          // Format: "entity.attribute±value"
+	Requires *condition.Expr // Condition that must hold to offer this choice (nil = always)
+	Correct  bool            // A right answer on a many-choice scene
+	Approach game.Approach   // How the choice tackles the problem, for karma (empty = untagged)
+	Rewards  game.Rewards    // Experience added to the scene's rewards when chosen
 }
 
-// ValidationResult is returned after validating a response
-type ValidationResult struct {
-	Valid       bool
-	Feedback    string
-	NextSceneID string
+// Branch routes to a scene when its condition holds
+type Branch struct {
+	When *condition.Expr
+	Next string
 }
 
-// Global cache for loaded scenes
-var sceneCache []Scene
-var sceneMap map[string]*Scene
+// NextFor returns the scene that follows this one for a player
+func (s *Scene) NextFor(env condition.Env) string {
+	return resolveNext(s.Branches, s.Next, env)
+}
 
-// GetScene returns a scene by ID
-func GetScene(id string) *Scene {
-	if sceneMap == nil {
-		loadScenes()
-	}
-	return sceneMap[id]
+// Targets returns every scene this scene can lead to directly
+func (s *Scene) Targets() []string {
+	return branchTargets(s.Branches, s.Next)
 }
 
-// GetPrefaceScenes returns all preface scenes loaded from YAML
-// Edit scenes/preface.yaml to write your story!
-func GetPrefaceScenes() []Scene {
-	if len(sceneCache) > 0 {
-		return sceneCache
-	}
-	loadScenes()
-	return sceneCache
+// Graded reports whether the player's answer to this scene is scored
+func (s *Scene) Graded() bool {
+	return s.Validator != nil || s.Selection != nil || s.Finisher != nil
 }
 
-// loadScenes loads and caches scenes from YAML
-func loadScenes() {
-	scenes, err := LoadScenesFromYAML("scenes/preface.yaml")
-	if err != nil {
-		// In production, this should panic or return error
-		// For now, return empty to allow graceful degradation
-		panic(fmt.Sprintf("Failed to load scenes: %v", err))
-	}
+// Weighted reports whether the scene's answer counts toward the chapter grade
+// A graded scene with weight 0 checks the answer without scoring it.
+func (s *Scene) Weighted() bool {
+	return s.Graded() && s.Weight > 0
+}
+
+// Gated reports whether the choice is hidden behind a condition
+func (c *Choice) Gated() bool {
+	return c.Requires != nil
+}
+
+// Available reports whether the choice is offered to a player
+func (c *Choice) Available(env condition.Env) bool {
+	return c.Requires.Eval(env)
+}
+
+// NextFor returns the scene this choice leads to for a player
+func (c *Choice) NextFor(env condition.Env) string {
+	return resolveNext(c.Branches, c.Next, env)
+}
 
-	sceneCache = scenes
+// Targets returns every scene this choice can lead to
+func (c *Choice) Targets() []string {
+	return branchTargets(c.Branches, c.Next)
+}
 
-	// Build scene map for quick lookup
-	sceneMap = make(map[string]*Scene)
-	for i := range sceneCache {
-		sceneMap[sceneCache[i].ID] = &sceneCache[i]
+// resolveNext picks the first branch whose condition holds, or the default
+func resolveNext(branches []Branch, def string, env condition.Env) string {
+	for _, b := range branches {
+		if b.When.Eval(env) {
+			return b.Next
+		}
 	}
+	return def
 }
 
-// LoadScenes allows explicitly loading scenes (useful for testing)
-func LoadScenes(scenes []Scene) {
-	sceneCache = scenes
-	sceneMap = make(map[string]*Scene)
-	for i := range sceneCache {
-		sceneMap[sceneCache[i].ID] = &sceneCache[i]
+// branchTargets lists branch targets followed by the default
+func branchTargets(branches []Branch, def string) []string {
+	targets := make([]string, 0, len(branches)+1)
+	for _, b := range branches {
+		targets = append(targets, b.Next)
 	}
+	return append(targets, def)
 }
+
+// ValidationResult is returned after validating a response
+type ValidationResult struct {
+	Valid       bool
+	Feedback    string
+	NextSceneID string
+}
+
+// ScenesDir is the directory scene files are loaded from
+const ScenesDir = "scenes"
@@ -0,0 +1,156 @@
+package story
+
+import (
+	"strings"
+
+	"github.com/jredh-dev/divine-academy/internal/game"
+)
+
+// Stats summarises the shape and size of a scene graph
+type Stats struct {
+	Scenes       int                `json:"scenes"`
+	ByThreadType map[ThreadType]int `json:"by_thread_type"`
+	Choices      int                `json:"choices"`
+	AvgBranching float64            `json:"avg_branching"` // Mean distinct destinations per scene
+	MaxBranching int                `json:"max_branching"`
+	ShortestPath int                `json:"shortest_path"` // Scenes on the shortest route from start to an ending
+	LongestPath  int                `json:"longest_path"`  // Scenes on the longest route, ignoring loops
+	Chapters     []ChapterStats     `json:"chapters"`
+}
+
+// ChapterStats summarises one chapter, identified by the scene ID prefix
+type ChapterStats struct {
+	Name   string `json:"name"`
+	Scenes int    `json:"scenes"`
+	Words  int    `json:"words"` // Narrative and choice text
+}
+
+// ComputeStats gathers statistics for a validated scene graph
+// start defaults to the first scene
+func ComputeStats(scenes []Scene, start string) Stats {
+	stats := Stats{
+		Scenes:       len(scenes),
+		ByThreadType: make(map[ThreadType]int),
+	}
+	if len(scenes) == 0 {
+		return stats
+	}
+	if start == "" {
+		start = scenes[0].ID
+	}
+
+	sceneMap := make(map[string]*Scene, len(scenes))
+	chapterIndex := make(map[string]int)
+	totalBranching := 0
+
+	for i := range scenes {
+		scene := &scenes[i]
+		sceneMap[scene.ID] = scene
+		stats.ByThreadType[scene.ThreadType]++
+		if scene.ThreadType == ThreadMulti {
+			stats.Choices += len(scene.Choices)
+		}
+
+		branching := len(distinct(scene.Edges()))
+		totalBranching += branching
+		stats.MaxBranching = max(stats.MaxBranching, branching)
+
+		name := ChapterOf(scene.ID)
+		idx, exists := chapterIndex[name]
+		if !exists {
+			idx = len(stats.Chapters)
+			chapterIndex[name] = idx
+			stats.Chapters = append(stats.Chapters, ChapterStats{Name: name})
+		}
+		stats.Chapters[idx].Scenes++
+		stats.Chapters[idx].Words += sceneWords(scene)
+	}
+
+	stats.AvgBranching = float64(totalBranching) / float64(len(scenes))
+	stats.ShortestPath = shortestPath(sceneMap, start)
+	stats.LongestPath = longestPath(sceneMap, start, make(map[string]int), make(map[string]bool))
+	return stats
+}
+
+// ChapterOf returns the chapter part of a scene ID (preface.2:campus-tour -> preface)
+func ChapterOf(id string) string {
+	return game.ChapterOf(id)
+}
+
+// sceneWords counts the words a player reads in a scene
+func sceneWords(scene *Scene) int {
+	words := len(strings.Fields(scene.Text))
+	for _, choice := range scene.Choices {
+		words += len(strings.Fields(choice.Text))
+	}
+	return words
+}
+
+// shortestPath counts the scenes on the shortest route from start to an ending
+// Returns 0 if no ending is reachable
+func shortestPath(sceneMap map[string]*Scene, start string) int {
+	depth := map[string]int{start: 1}
+	queue := []string{start}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		scene, exists := sceneMap[id]
+		if !exists {
+			continue
+		}
+		for _, next := range scene.Edges() {
+			if isExit(next) {
+				return depth[id]
+			}
+			if _, seen := depth[next]; !seen {
+				depth[next] = depth[id] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return 0
+}
+
+// longestPath counts the scenes on the longest route from a scene to an
+// ending, skipping edges that loop back onto the current route
+func longestPath(sceneMap map[string]*Scene, id string, memo map[string]int, onPath map[string]bool) int {
+	if n, done := memo[id]; done {
+		return n
+	}
+	scene, exists := sceneMap[id]
+	if !exists {
+		return 0
+	}
+
+	onPath[id] = true
+	best := 0
+	for _, next := range scene.Edges() {
+		switch {
+		case isExit(next):
+			best = max(best, 1)
+		case onPath[next]:
+			// Loop back; not part of a simple route
+		default:
+			if n := longestPath(sceneMap, next, memo, onPath); n > 0 {
+				best = max(best, n+1)
+			}
+		}
+	}
+	onPath[id] = false
+
+	memo[id] = best
+	return best
+}
+
+// distinct removes duplicate strings, keeping the first occurrence
+func distinct(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := values[:0:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package story
+
+import (
+	"testing"
+)
+
+func TestComputeStats(t *testing.T) {
+	path := writeScenes(t, `
+scenes:
+  - id: intro.0:start
+    thread_type: multi
+    text: One two three
+    choices:
+      - text: Short way
+        next: intro.1:short
+      - text: Long way
+        next: chapter1.0:long
+      - text: Also long
+        next: chapter1.0:long
+  - id: intro.1:short
+    thread_type: affirmative
+    text: Four five
+    next: 0
+  - id: chapter1.0:long
+    thread_type: open
+    text: Six
+    next: chapter1.1:longer
+  - id: chapter1.1:longer
+    thread_type: affirmative
+    text: Seven eight nine ten
+    next: 0
+`)
+
+	scenes, err := LoadScenesFromYAML(path)
+	if err != nil {
+		t.Fatalf("Failed to load scenes: %v", err)
+	}
+	stats := ComputeStats(scenes, "")
+
+	if stats.Scenes != 4 {
+		t.Errorf("Scenes = %d, want 4", stats.Scenes)
+	}
+	if stats.ByThreadType[ThreadMulti] != 1 || stats.ByThreadType[ThreadAffirmative] != 2 || stats.ByThreadType[ThreadOpen] != 1 {
+		t.Errorf("ByThreadType = %v", stats.ByThreadType)
+	}
+	if stats.Choices != 3 {
+		t.Errorf("Choices = %d, want 3", stats.Choices)
+	}
+	// start has 2 distinct destinations, the others 1 each
+	if stats.MaxBranching != 2 || stats.AvgBranching != 1.25 {
+		t.Errorf("Branching = avg %.2f max %d, want avg 1.25 max 2", stats.AvgBranching, stats.MaxBranching)
+	}
+	if stats.ShortestPath != 2 {
+		t.Errorf("ShortestPath = %d, want 2", stats.ShortestPath)
+	}
+	if stats.LongestPath != 3 {
+		t.Errorf("LongestPath = %d, want 3", stats.LongestPath)
+	}
+
+	if len(stats.Chapters) != 2 {
+		t.Fatalf("Chapters = %v, want 2", stats.Chapters)
+	}
+	intro, ch1 := stats.Chapters[0], stats.Chapters[1]
+	// intro: 3 + 2 narrative words, 2 + 2 + 2 choice words
+	if intro.Name != "intro" || intro.Scenes != 2 || intro.Words != 11 {
+		t.Errorf("intro = %+v, want 2 scenes 11 words", intro)
+	}
+	if ch1.Name != "chapter1" || ch1.Scenes != 2 || ch1.Words != 5 {
+		t.Errorf("chapter1 = %+v, want 2 scenes 5 words", ch1)
+	}
+}
+
+func TestComputeStats_Loop(t *testing.T) {
+	// A loop must not make the longest path infinite
+	scenes := []Scene{
+		{ID: "a.0:start", ThreadType: ThreadMulti, Choices: []Choice{
+			{Text: "Again", Next: "a.1:loop"},
+			{Text: "Stop", Next: "0"},
+		}},
+		{ID: "a.1:loop", ThreadType: ThreadAffirmative, Next: "a.0:start"},
+	}
+
+	stats := ComputeStats(scenes, "")
+	if stats.ShortestPath != 1 {
+		t.Errorf("ShortestPath = %d, want 1", stats.ShortestPath)
+	}
+	if stats.LongestPath != 1 {
+		t.Errorf("LongestPath = %d, want 1", stats.LongestPath)
+	}
+}
+
+func TestChapterOf(t *testing.T) {
+	tests := map[string]string{
+		"preface.0:dream-start": "preface",
+		"chapter1.5:boss-fight": "chapter1",
+		"0":                     "0",
+	}
+	for id, want := range tests {
+		if got := ChapterOf(id); got != want {
+			t.Errorf("ChapterOf(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
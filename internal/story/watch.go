@@ -0,0 +1,96 @@
+package story
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultPollInterval is how often a Watcher checks for changes
+const DefaultPollInterval = time.Second
+
+// Watcher polls a scenes directory and reports when any file in it changes
+// Polling avoids platform-specific file notification APIs and is cheap for a
+// directory of a few dozen YAML files
+type Watcher struct {
+	Dir      string
+	Interval time.Duration
+
+	last string // Fingerprint from the previous poll
+}
+
+// NewWatcher creates a watcher for dir, taking the current contents as the baseline
+func NewWatcher(dir string, interval time.Duration) (*Watcher, error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	w := &Watcher{Dir: dir, Interval: interval}
+	last, err := fingerprint(dir)
+	if err != nil {
+		return nil, err
+	}
+	w.last = last
+	return w, nil
+}
+
+// Changed reports whether the directory has changed since the last call
+func (w *Watcher) Changed() (bool, error) {
+	next, err := fingerprint(w.Dir)
+	if err != nil {
+		return false, err
+	}
+	if next == w.last {
+		return false, nil
+	}
+	w.last = next
+	return true, nil
+}
+
+// Run polls until ctx is cancelled, calling onChange after each change
+// Errors reading the directory are passed to onError and polling continues
+func (w *Watcher) Run(ctx context.Context, onChange func(), onError func(error)) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := w.Changed()
+			if err != nil {
+				onError(err)
+				continue
+			}
+			if changed {
+				onChange()
+			}
+		}
+	}
+}
+
+// fingerprint summarises the name, size, and modification time of every file under dir
+func fingerprint(dir string) (string, error) {
+	var b strings.Builder
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "%s|%d|%d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,51 @@
+package story
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher_Changed(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "preface.yaml")
+	if err := os.WriteFile(file, []byte("scenes: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWatcher(dir, 0)
+	if err != nil {
+		t.Fatalf("NewWatcher() error: %v", err)
+	}
+	if w.Interval != DefaultPollInterval {
+		t.Errorf("Interval = %v, want %v", w.Interval, DefaultPollInterval)
+	}
+
+	if changed, err := w.Changed(); err != nil || changed {
+		t.Errorf("Changed() = %v, %v before any edit, want false, nil", changed, err)
+	}
+
+	// Bump the modification time so the edit is seen on coarse-grained filesystems
+	if err := os.WriteFile(file, []byte("scenes: [ ]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(file, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := w.Changed(); err != nil || !changed {
+		t.Errorf("Changed() = %v, %v after edit, want true, nil", changed, err)
+	}
+	if changed, _ := w.Changed(); changed {
+		t.Error("Changed() should report each edit only once")
+	}
+
+	// New files count as changes too
+	if err := os.WriteFile(filepath.Join(dir, "chapter1.yaml"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, _ := w.Changed(); !changed {
+		t.Error("Changed() should report a new file")
+	}
+}
@@ -2,10 +2,17 @@ package story
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
 
+	"github.com/jredh-dev/divine-academy/internal/condition"
+	"github.com/jredh-dev/divine-academy/internal/game"
+	"github.com/jredh-dev/divine-academy/internal/impact"
 	"gopkg.in/yaml.v3"
 )
 
@@ -14,28 +21,181 @@ type ThreadType string
 
 const (
 	ThreadMulti       ThreadType = "multi"       // Multiple choice with different next scenes
+	ThreadMany        ThreadType = "many"        // Select every correct answer; partial credit, one next scene
 	ThreadOpen        ThreadType = "open"        // Text input with validation
 	ThreadAffirmative ThreadType = "affirmative" // Simple "Continue" button
-	ThreadFinisher    ThreadType = "finisher"    // (Stubbed) Real-time tokenization
+	ThreadFinisher    ThreadType = "finisher"    // Type out a passage, checked word by word as it is typed
+	ThreadAbility     ThreadType = "ability"     // Spend ability points on abilities from the catalogue
 )
 
 // YAMLScene represents a scene as defined in YAML
 type YAMLScene struct {
-	ID         string       `yaml:"id"`
-	ThreadType ThreadType   `yaml:"thread_type"`
-	Text       string       `yaml:"text"`
-	Choices    []YAMLChoice `yaml:"choices,omitempty"`
-	Validation *struct {
-		MinLength int `yaml:"min_length"`
-	} `yaml:"validation,omitempty"`
-	Next string `yaml:"next,omitempty"` // For open/affirmative/finisher
+	ID         string          `yaml:"id"`
+	ThreadType ThreadType      `yaml:"thread_type"`
+	Text       string          `yaml:"text"`
+	Choices    []YAMLChoice    `yaml:"choices,omitempty"`
+	Validation *YAMLValidation `yaml:"validation,omitempty"`
+	Next       YAMLNext        `yaml:"next,omitempty"`    // For open/affirmative/finisher/many
+	Target     string          `yaml:"target,omitempty"`  // Passage the player completes in a finisher scene
+	Teaches    []string        `yaml:"teaches,omitempty"` // Concepts learned on entering, checked by knows()
+	Weight     *float64        `yaml:"weight,omitempty"`  // Importance in the chapter grade (default 1, 0 = ungraded)
+	Hints      []string        `yaml:"hints,omitempty"`   // Genie hints, revealed one tier per rub
+	Rewards    YAMLRewards     `yaml:"rewards,omitempty"`
+	Abilities  []string        `yaml:"abilities,omitempty"` // Catalogue IDs offered on an ability scene
+}
+
+// YAMLRewards is the experience granted per track when a scene is completed:
+//
+//	rewards:
+//	  mental: 5
+//	  emotional: 2
+//
+// Graded scenes grant them in proportion to the answer's score.
+type YAMLRewards map[string]int
+
+// YAMLValidation configures how an open response is checked
+type YAMLValidation struct {
+	MinLength     int                `yaml:"min_length"`
+	Keywords      []YAMLKeyword      `yaml:"keywords,omitempty"`       // Accepted keywords or groups of alternatives
+	Forbidden     []string           `yaml:"forbidden,omitempty"`      // Keywords that make an answer wrong
+	Stem          bool               `yaml:"stem,omitempty"`           // Match inflections (divide/divided/dividing)
+	Patterns      []YAMLPattern      `yaml:"patterns,omitempty"`       // Regular expressions the answer must (not) match
+	RequiredCount int                `yaml:"required_count,omitempty"` // Keywords needed (default 1)
+	CaseSensitive bool               `yaml:"case_sensitive,omitempty"`
+	Partial       bool               `yaml:"partial,omitempty"`      // Match keywords inside words
+	Fuzzy         bool               `yaml:"fuzzy,omitempty"`        // Accept misspelled keywords
+	MaxDistance   int                `yaml:"max_distance,omitempty"` // Edits allowed by fuzzy matching (default by length)
+	Distances     map[string]int     `yaml:"distances,omitempty"`    // Per-keyword edit limits
+	Numeric       *YAMLNumericAnswer `yaml:"numeric,omitempty"`
+	Expression    *YAMLExpression    `yaml:"expression,omitempty"`
+}
+
+// YAMLKeyword is one accepted concept, written as a single keyword, a list of
+// alternatives, or a named group:
+//
+//	keywords:
+//	  - division
+//	  - [WWI, World War One, the Great War]
+//	  - name: treaty
+//	    any: [Versailles, peace treaty]
+type YAMLKeyword struct {
+	Name string   `yaml:"name,omitempty"`
+	Any  []string `yaml:"any"`
+}
+
+// UnmarshalYAML accepts the scalar, list, and mapping forms of a keyword
+func (k *YAMLKeyword) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		k.Any = []string{node.Value}
+		return nil
+	case yaml.SequenceNode:
+		return node.Decode(&k.Any)
+	case yaml.MappingNode:
+		type plain YAMLKeyword // Avoids recursing into this method
+		return node.Decode((*plain)(k))
+	default:
+		return fmt.Errorf("line %d: a keyword must be a word, a list of alternatives, or {name, any}", node.Line)
+	}
+}
+
+// label names the keyword in validation results and distance settings
+func (k YAMLKeyword) label() string {
+	if k.Name != "" || len(k.Any) == 0 {
+		return k.Name
+	}
+	return k.Any[0]
+}
+
+// YAMLPattern is a regular expression rule for an open response
+// Patterns ignore case unless the validation block is case_sensitive
+type YAMLPattern struct {
+	Name      string `yaml:"name,omitempty"` // Shown to the player (default: the regex)
+	Regex     string `yaml:"regex"`
+	Forbidden bool   `yaml:"forbidden,omitempty"` // Reject answers that match
+}
+
+// YAMLNumericAnswer configures a numeric open response
+//
+//	numeric:
+//	  answers: [12]
+//	  unit: cm              # answers in mm, m, inches... are converted
+//	  require_unit: true
+//	  relative_tolerance: 0.05
+//	  ranges:
+//	    - {min: 11, max: 13}
+//
+// Without a unit, a percentage answer matches either reading: 25% is
+// accepted for an answer of 0.25 or of 25.
+type YAMLNumericAnswer struct {
+	Answers           []float64   `yaml:"answers"`
+	Tolerance         float64     `yaml:"tolerance,omitempty"`
+	RelativeTolerance float64     `yaml:"relative_tolerance,omitempty"` // Fraction of the answer, 0.05 = 5%
+	Ranges            []YAMLRange `yaml:"ranges,omitempty"`
+	Unit              string      `yaml:"unit,omitempty"`
+	RequireUnit       bool        `yaml:"require_unit,omitempty"`
+}
+
+// YAMLRange is an inclusive range of accepted numeric answers
+type YAMLRange struct {
+	Min float64 `yaml:"min"`
+	Max float64 `yaml:"max"`
+}
+
+// YAMLExpression configures an algebra answer; any equivalent expression is accepted
+//
+//	expression:
+//	  answer: 2(x+3)       # 2x+6 and 6+2x are also correct
+//	  slip_score: 0.5      # credit for one arithmetic slip, 0 for none
+type YAMLExpression struct {
+	Answer    string   `yaml:"answer"`
+	SlipScore *float64 `yaml:"slip_score,omitempty"` // Default game.DefaultSlipScore; 0 turns partial credit off
 }
 
 // YAMLChoice represents a choice option in YAML
 type YAMLChoice struct {
-	Text   string `yaml:"text"`
-	Next   string `yaml:"next"`
-	Impact string `yaml:"impact,omitempty"` // Format: "entity.attribute±value"
+	Text     string      `yaml:"text"`
+	Next     YAMLNext    `yaml:"next"`
+	Impact   string      `yaml:"impact,omitempty"`   // Format: "entity.attribute±value"
+	Requires string      `yaml:"requires,omitempty"` // Condition, e.g. "player.intelligence >= 3"
+	Correct  bool        `yaml:"correct,omitempty"`  // A right answer on a many-choice scene
+	Approach string      `yaml:"approach,omitempty"` // empathize, relate, or coerce; sets karma, earns equal XP
+	Rewards  YAMLRewards `yaml:"rewards,omitempty"`  // Added to the scene's rewards when chosen
+}
+
+// YAMLNext is either a single scene ID or an ordered list of branches:
+//
+//	next:
+//	  - when: player.intelligence >= 3
+//	    next: preface.3:advanced-class
+//	  - next: preface.3:regular-class   # default, no 'when'
+type YAMLNext struct {
+	Target   string       // Single scene ID form
+	Branches []YAMLBranch // Branching list form
+}
+
+// YAMLBranch is one entry of a branching 'next' list
+type YAMLBranch struct {
+	When string `yaml:"when,omitempty"` // Empty for the default branch
+	Next string `yaml:"next"`
+}
+
+// UnmarshalYAML accepts both the scalar and list forms of 'next'
+func (n *YAMLNext) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		n.Target = node.Value
+		return nil
+	case yaml.SequenceNode:
+		return node.Decode(&n.Branches)
+	default:
+		return fmt.Errorf("line %d: 'next' must be a scene ID or a list of {when, next} branches", node.Line)
+	}
+}
+
+// IsZero reports whether 'next' was left out
+func (n YAMLNext) IsZero() bool {
+	return n.Target == "" && len(n.Branches) == 0
 }
 
 // YAMLSceneFile represents the top-level YAML structure
@@ -44,53 +204,208 @@ type YAMLSceneFile struct {
 }
 
 // LoadScenesFromYAML loads scenes from a YAML file and validates the graph
+// Abilities come from the abilities.yaml beside the file, if there is one.
+// On failure the error is a Diagnostics list
 func LoadScenesFromYAML(filename string) ([]Scene, error) {
+	abilities, err := loadCatalogueIn(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+	scenes, _, err := loadSceneFiles([]string{filename}, LoadOptions{Abilities: abilities})
+	return scenes, err
+}
+
+// loadSceneFiles parses several scene files in order, merges them into one
+// graph and validates it, so 'next' may reference scenes in other files.
+// Graph analysis findings fail the load in strict mode and are returned
+// as warnings in warn mode. On failure the error is a Diagnostics list.
+func loadSceneFiles(files []string, opts LoadOptions) ([]Scene, Diagnostics, error) {
+	var scenes []Scene
+	var diags Diagnostics
+	for _, filename := range files {
+		fileScenes, fileDiags := parseSceneFile(filename)
+		scenes = append(scenes, fileScenes...)
+		diags = append(diags, fileDiags...)
+	}
+
+	// Validate the scene graph
+	diags = append(diags, resolveAbilities(scenes, opts.Abilities)...)
+	diags = append(diags, validateSceneGraph(scenes)...)
+	if len(diags) > 0 {
+		return nil, nil, diags
+	}
+
+	if opts.Start != "" && !hasScene(scenes, opts.Start) {
+		return nil, nil, Diagnostics{{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("start scene '%s' does not exist", opts.Start),
+		}}
+	}
+
+	severity := SeverityWarning
+	if opts.Mode == ModeStrict {
+		severity = SeverityError
+	}
+
+	var findings Diagnostics
+	for _, issue := range AnalyzeGraph(scenes, opts.Start) {
+		findings = append(findings, issue.Diagnostic(severity))
+	}
+	if findings.HasErrors() {
+		return nil, nil, findings
+	}
+
+	return scenes, findings, nil
+}
+
+// hasScene reports whether a scene ID is present
+func hasScene(scenes []Scene, id string) bool {
+	for i := range scenes {
+		if scenes[i].ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSceneFile reads and converts the scenes in a single YAML file
+// Scenes are decoded node by node so each scene and field keeps its source
+// position. Scenes that fail to convert are reported and left out.
+func parseSceneFile(filename string) ([]Scene, Diagnostics) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, Diagnostics{{Severity: SeverityError, File: filename, Message: fmt.Sprintf("failed to read file: %v", err)}}
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, yamlDiagnostics(filename, err)
+	}
+	if root.Kind == 0 {
+		// Empty file
+		return nil, nil
 	}
 
-	var sceneFile YAMLSceneFile
-	if err := yaml.Unmarshal(data, &sceneFile); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	var sceneFile struct {
+		Scenes []yaml.Node `yaml:"scenes"`
+	}
+	if err := root.Decode(&sceneFile); err != nil {
+		return nil, yamlDiagnostics(filename, err)
 	}
 
 	// Convert YAML scenes to Scene structs
 	scenes := make([]Scene, 0, len(sceneFile.Scenes))
-	sceneMap := make(map[string]*Scene) // For quick lookup during validation
+	var diags Diagnostics
+	for i := range sceneFile.Scenes {
+		node := &sceneFile.Scenes[i]
+
+		var yamlScene YAMLScene
+		if err := node.Decode(&yamlScene); err != nil {
+			diags = append(diags, yamlDiagnostics(filename, err)...)
+			continue
+		}
 
-	for _, yamlScene := range sceneFile.Scenes {
-		scene, err := convertYAMLScene(yamlScene)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert scene %s: %w", yamlScene.ID, err)
+		scene, errs := convertYAMLScene(yamlScene)
+		scene.Pos = Position{File: filename, Line: node.Line, Column: node.Column}
+		scene.fields = make(map[string]Position)
+		recordFields(node, filename, "", scene.fields)
+
+		if len(errs) > 0 {
+			for _, err := range errs {
+				diags = append(diags, diagnoseErr(&scene, err))
+			}
+			continue
 		}
 		scenes = append(scenes, scene)
-		sceneMap[scene.ID] = &scenes[len(scenes)-1]
 	}
 
-	// Validate the scene graph
-	if err := validateSceneGraph(scenes, sceneMap); err != nil {
-		return nil, fmt.Errorf("scene graph validation failed: %w", err)
-	}
-
-	return scenes, nil
+	return scenes, diags
 }
 
 // convertYAMLScene converts a YAMLScene to a Scene
-func convertYAMLScene(yamlScene YAMLScene) (Scene, error) {
+// Every problem found is returned, attributed to its field where possible
+func convertYAMLScene(yamlScene YAMLScene) (Scene, []error) {
+	var errs []error
+
 	// Validate ID format: chapter.scene-number:description
 	if err := validateSceneID(yamlScene.ID); err != nil {
-		return Scene{}, err
+		errs = append(errs, atField("id", err))
 	}
 
 	// Convert choices
 	choices := make([]Choice, 0, len(yamlScene.Choices))
-	for _, yamlChoice := range yamlScene.Choices {
-		choices = append(choices, Choice{
-			Text:   yamlChoice.Text,
-			Next:   yamlChoice.Next,
-			Impact: yamlChoice.Impact,
-		})
+	for i, yamlChoice := range yamlScene.Choices {
+		field := fmt.Sprintf("choices[%d]", i)
+
+		next, branches, err := convertYAMLNext(field+".next", yamlChoice.Next)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		choice := Choice{
+			Text:     yamlChoice.Text,
+			Next:     next,
+			Branches: branches,
+			Impact:   yamlChoice.Impact,
+			Correct:  yamlChoice.Correct,
+		}
+
+		// Many-choice options are answers, not routes
+		if yamlScene.ThreadType == ThreadMany {
+			if next != "" || len(branches) > 0 {
+				errs = append(errs, atField(field+".next", fmt.Errorf("choices on thread_type 'many' cannot have next; the scene's next is used")))
+			}
+			if yamlChoice.Requires != "" {
+				errs = append(errs, atField(field+".requires", fmt.Errorf("choices on thread_type 'many' cannot have requires")))
+			}
+		} else if yamlChoice.Correct {
+			errs = append(errs, atField(field+".correct", fmt.Errorf("correct is only supported on thread_type 'many'")))
+		}
+
+		// Approaches are ways of acting, so only routed choices carry them
+		if yamlChoice.Approach != "" {
+			approach, err := game.ParseApproach(yamlChoice.Approach)
+			switch {
+			case err != nil:
+				errs = append(errs, atField(field+".approach", err))
+			case yamlScene.ThreadType != ThreadMulti:
+				errs = append(errs, atField(field+".approach", fmt.Errorf("approach is only supported on thread_type 'multi'")))
+			default:
+				choice.Approach = approach
+			}
+		}
+
+		if len(yamlChoice.Rewards) > 0 {
+			if yamlScene.ThreadType != ThreadMulti {
+				errs = append(errs, atField(field+".rewards", fmt.Errorf("choice rewards are only supported on thread_type 'multi'")))
+			}
+			rewards, err := convertYAMLRewards(field+".rewards", yamlChoice.Rewards)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			choice.Rewards = rewards
+		}
+
+		// Parse and type-check the gating condition
+		if yamlChoice.Requires != "" {
+			requires, err := condition.Parse(yamlChoice.Requires)
+			if err != nil {
+				errs = append(errs, atField(field+".requires", fmt.Errorf("invalid requires '%s': %w", yamlChoice.Requires, err)))
+			}
+			choice.Requires = requires
+		}
+
+		choices = append(choices, choice)
+	}
+
+	// Every approach is worth the same experience; only karma differs
+	if field, ok := unequalApproachRewards(choices); !ok {
+		errs = append(errs, atField(field+".rewards", fmt.Errorf("choices with an approach must all have the same rewards")))
+	}
+
+	next, branches, err := convertYAMLNext("next", yamlScene.Next)
+	if err != nil {
+		errs = append(errs, err)
 	}
 
 	// Create scene
@@ -99,15 +414,405 @@ func convertYAMLScene(yamlScene YAMLScene) (Scene, error) {
 		ThreadType: yamlScene.ThreadType,
 		Text:       strings.TrimSpace(yamlScene.Text),
 		Choices:    choices,
-		Next:       yamlScene.Next, // For open/affirmative/finisher
+		Next:       next, // For open/affirmative/finisher
+		Branches:   branches,
+		Teaches:    yamlScene.Teaches,
+	}
+
+	rewards, err := convertYAMLRewards("rewards", yamlScene.Rewards)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	scene.Rewards = rewards
+
+	// Ability scenes offer catalogue entries, resolved once the catalogue is loaded
+	switch {
+	case yamlScene.ThreadType == ThreadAbility && len(yamlScene.Abilities) == 0:
+		errs = append(errs, atField("abilities", fmt.Errorf("thread_type 'ability' needs at least one ability")))
+	case yamlScene.ThreadType != ThreadAbility && len(yamlScene.Abilities) > 0:
+		errs = append(errs, atField("abilities", fmt.Errorf("abilities is only supported on thread_type 'ability'")))
+	}
+	if yamlScene.ThreadType == ThreadAbility && len(choices) > 0 {
+		errs = append(errs, atField("choices", fmt.Errorf("thread_type 'ability' offers its abilities instead of choices")))
+	}
+	for _, id := range yamlScene.Abilities {
+		scene.Abilities = append(scene.Abilities, game.Ability{ID: strings.TrimSpace(id)})
+	}
+
+	// Hints are revealed in order, so later tiers can give more away
+	if len(yamlScene.Hints) > 0 && yamlScene.ThreadType == ThreadAffirmative {
+		errs = append(errs, atField("hints", fmt.Errorf("hints are not supported on thread_type 'affirmative'")))
+	}
+	for i, hint := range yamlScene.Hints {
+		hint = strings.TrimSpace(hint)
+		if hint == "" {
+			errs = append(errs, atField(fmt.Sprintf("hints[%d]", i), fmt.Errorf("hint %d is empty", i)))
+		}
+		scene.Hints = append(scene.Hints, hint)
 	}
 
 	// Add validation for open responses
-	if yamlScene.ThreadType == ThreadOpen && yamlScene.Validation != nil {
+	if yamlScene.Validation != nil {
+		if yamlScene.ThreadType != ThreadOpen {
+			errs = append(errs, atField("validation", fmt.Errorf("validation is only supported on thread_type 'open'")))
+		}
 		scene.MinLength = yamlScene.Validation.MinLength
+
+		validator, err := buildValidator(yamlScene.Validation)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		scene.Validator = validator
+	}
+
+	// Many-choice scenes are scored against the choices marked correct
+	if yamlScene.ThreadType == ThreadMany {
+		var correct []int
+		for i, choice := range choices {
+			if choice.Correct {
+				correct = append(correct, i)
+			}
+		}
+		if len(correct) == 0 && len(choices) > 0 {
+			errs = append(errs, atField("choices", fmt.Errorf("thread_type 'many' needs at least one choice marked correct")))
+		}
+		scene.Selection = &game.MultipleChoiceValidator{CorrectIndices: correct, AllowMultiple: true}
+	}
+
+	// Finisher scenes are completed by typing out the target passage
+	target := strings.TrimSpace(yamlScene.Target)
+	switch {
+	case yamlScene.ThreadType == ThreadFinisher && target == "":
+		errs = append(errs, atField("target", fmt.Errorf("finisher scenes need a target passage")))
+	case yamlScene.ThreadType != ThreadFinisher && target != "":
+		errs = append(errs, atField("target", fmt.Errorf("target is only supported on thread_type 'finisher'")))
+	case target != "":
+		scene.Finisher = game.NewFinisherValidator(target)
+	}
+
+	// Graded scenes count toward the chapter grade by their weight
+	switch {
+	case !scene.Graded() && yamlScene.Weight != nil:
+		errs = append(errs, atField("weight", fmt.Errorf("weight is only supported on graded scenes (open with validation, many, or finisher)")))
+	case yamlScene.Weight != nil && *yamlScene.Weight < 0:
+		errs = append(errs, atField("weight", fmt.Errorf("weight must not be negative")))
+	case yamlScene.Weight != nil:
+		scene.Weight = *yamlScene.Weight
+	case scene.Graded():
+		scene.Weight = 1
+	}
+
+	return scene, errs
+}
+
+// convertYAMLRewards checks reward tracks and amounts
+func convertYAMLRewards(field string, y YAMLRewards) (game.Rewards, error) {
+	if len(y) == 0 {
+		return nil, nil
+	}
+	rewards := make(game.Rewards, len(y))
+	for name, xp := range y {
+		track, err := game.ParseTrack(name)
+		if err != nil {
+			return nil, atField(field, err)
+		}
+		if xp <= 0 {
+			return nil, atField(field+"."+name, fmt.Errorf("reward must be a positive amount of experience, got %d", xp))
+		}
+		rewards[track] += xp
+	}
+	return rewards, nil
+}
+
+// unequalApproachRewards finds the first approach-tagged choice whose rewards
+// differ from the others, returning its field
+func unequalApproachRewards(choices []Choice) (string, bool) {
+	first := -1
+	for i, choice := range choices {
+		if choice.Approach == game.ApproachNone {
+			continue
+		}
+		if first < 0 {
+			first = i
+			continue
+		}
+		if !maps.Equal(choice.Rewards, choices[first].Rewards) {
+			return fmt.Sprintf("choices[%d]", i), false
+		}
+	}
+	return "", true
+}
+
+// buildValidator creates the answer validator described by a validation block
+// Returns nil if the block only sets min_length
+func buildValidator(v *YAMLValidation) (game.Validator, error) {
+	if v.MinLength < 0 {
+		return nil, atField("validation.min_length", fmt.Errorf("min_length must not be negative"))
+	}
+
+	if v.Expression != nil {
+		if v.Numeric != nil || len(v.Keywords) > 0 || len(v.Patterns) > 0 || len(v.Forbidden) > 0 {
+			return nil, atField("validation", fmt.Errorf("expression cannot be combined with keywords, patterns, or numeric"))
+		}
+		if name, ok := keywordOption(v); ok {
+			return nil, atField("validation."+name, fmt.Errorf("%s only applies to keywords and patterns, not expression", name))
+		}
+		return buildExpressionValidator(v.Expression)
+	}
+
+	if v.Numeric != nil {
+		if len(v.Keywords) > 0 || len(v.Patterns) > 0 {
+			return nil, atField("validation", fmt.Errorf("use either keywords and patterns or numeric, not both"))
+		}
+		if name, ok := keywordOption(v); ok {
+			return nil, atField("validation."+name, fmt.Errorf("%s only applies to keywords and patterns, not numeric", name))
+		}
+		return buildNumericValidator(v.Numeric)
+	}
+
+	if len(v.Keywords) == 0 {
+		if v.RequiredCount > 0 {
+			return nil, atField("validation.required_count", fmt.Errorf("required_count is set but no keywords are listed"))
+		}
+		if v.Fuzzy {
+			return nil, atField("validation.fuzzy", fmt.Errorf("fuzzy is set but no keywords are listed"))
+		}
+		if len(v.Forbidden) == 0 && len(v.Patterns) == 0 {
+			if v.Stem {
+				return nil, atField("validation.stem", fmt.Errorf("stem is set but no keywords are listed"))
+			}
+			return nil, nil
+		}
+	}
+
+	groups := make([]game.KeywordGroup, len(v.Keywords))
+	for i, kw := range v.Keywords {
+		field := fmt.Sprintf("validation.keywords[%d]", i)
+		if len(kw.Any) == 0 {
+			return nil, atField(field, fmt.Errorf("keyword %d has no alternatives", i))
+		}
+		for j, alt := range kw.Any {
+			if strings.TrimSpace(alt) == "" {
+				if len(kw.Any) == 1 {
+					return nil, atField(field, fmt.Errorf("keyword %d is empty", i))
+				}
+				return nil, atField(field, fmt.Errorf("keyword %d alternative %d is empty", i, j))
+			}
+		}
+		groups[i] = game.KeywordGroup{Name: kw.Name, Alternatives: kw.Any}
+	}
+
+	for i, kw := range v.Forbidden {
+		if strings.TrimSpace(kw) == "" {
+			return nil, atField(fmt.Sprintf("validation.forbidden[%d]", i), fmt.Errorf("forbidden keyword %d is empty", i))
+		}
+	}
+
+	patterns, err := compilePatterns(v.Patterns, v.CaseSensitive)
+	if err != nil {
+		return nil, err
+	}
+
+	// A block with only forbidden keywords accepts any answer that avoids them
+	required := v.RequiredCount
+	if required == 0 && len(v.Keywords) > 0 {
+		required = 1
+	}
+	if required < 0 || required > len(v.Keywords) {
+		return nil, atField("validation.required_count", fmt.Errorf("required_count %d must be between 1 and the number of keywords (%d)", v.RequiredCount, len(v.Keywords)))
+	}
+
+	if err := validateFuzzy(v); err != nil {
+		return nil, err
+	}
+
+	validator := game.NewValidator(nil, required)
+	validator.Groups = groups
+	validator.Forbidden = v.Forbidden
+	validator.Patterns = patterns
+	validator.CaseSensitive = v.CaseSensitive
+	validator.AllowPartial = v.Partial
+	validator.Stem = v.Stem
+	validator.Fuzzy = v.Fuzzy
+	validator.MaxDistance = v.MaxDistance
+	validator.Distances = v.Distances
+	return validator, nil
+}
+
+// buildNumericValidator creates the validator for a numeric answer block
+func buildNumericValidator(n *YAMLNumericAnswer) (game.Validator, error) {
+	if len(n.Answers) == 0 && len(n.Ranges) == 0 {
+		return nil, atField("validation.numeric", fmt.Errorf("numeric requires at least one answer or range"))
+	}
+	if n.Tolerance < 0 {
+		return nil, atField("validation.numeric.tolerance", fmt.Errorf("numeric tolerance must not be negative"))
+	}
+	if n.RelativeTolerance < 0 || n.RelativeTolerance >= 1 {
+		return nil, atField("validation.numeric.relative_tolerance", fmt.Errorf("relative_tolerance must be at least 0 and below 1"))
+	}
+	if n.RequireUnit && n.Unit == "" {
+		return nil, atField("validation.numeric.require_unit", fmt.Errorf("require_unit is set but no unit is given"))
+	}
+
+	ranges := make([]game.NumericRange, len(n.Ranges))
+	for i, r := range n.Ranges {
+		if r.Min > r.Max {
+			return nil, atField(fmt.Sprintf("validation.numeric.ranges[%d]", i), fmt.Errorf("range min %g is greater than max %g", r.Min, r.Max))
+		}
+		ranges[i] = game.NumericRange{Min: r.Min, Max: r.Max}
+	}
+
+	return &game.NumericValidator{
+		AcceptedValues:    n.Answers,
+		Tolerance:         n.Tolerance,
+		RelativeTolerance: n.RelativeTolerance,
+		Ranges:            ranges,
+		Unit:              n.Unit,
+		RequireUnit:       n.RequireUnit,
+	}, nil
+}
+
+// keywordOption returns the first option set in a validation block that only
+// affects keyword and pattern matching
+func keywordOption(v *YAMLValidation) (string, bool) {
+	options := []struct {
+		name string
+		set  bool
+	}{
+		{"forbidden", len(v.Forbidden) > 0},
+		{"stem", v.Stem},
+		{"required_count", v.RequiredCount != 0},
+		{"case_sensitive", v.CaseSensitive},
+		{"partial", v.Partial},
+		{"fuzzy", v.Fuzzy},
+		{"max_distance", v.MaxDistance != 0},
+		{"distances", len(v.Distances) > 0},
+	}
+	for _, option := range options {
+		if option.set {
+			return option.name, true
+		}
+	}
+	return "", false
+}
+
+// buildExpressionValidator creates the validator for an expression answer block
+func buildExpressionValidator(e *YAMLExpression) (game.Validator, error) {
+	if strings.TrimSpace(e.Answer) == "" {
+		return nil, atField("validation.expression", fmt.Errorf("expression requires an answer"))
+	}
+	if e.SlipScore != nil && (*e.SlipScore < 0 || *e.SlipScore > 1) {
+		return nil, atField("validation.expression.slip_score", fmt.Errorf("slip_score must be between 0 and 1"))
+	}
+
+	validator, err := game.NewExpressionValidator(e.Answer)
+	if err != nil {
+		return nil, atField("validation.expression.answer", fmt.Errorf("invalid expression answer: %w", err))
+	}
+	if e.SlipScore != nil {
+		validator.SlipScore = *e.SlipScore
+	}
+	return validator, nil
+}
+
+// compilePatterns compiles the pattern rules of a validation block
+func compilePatterns(patterns []YAMLPattern, caseSensitive bool) ([]game.PatternRule, error) {
+	rules := make([]game.PatternRule, len(patterns))
+	for i, p := range patterns {
+		field := fmt.Sprintf("validation.patterns[%d]", i)
+		if p.Regex == "" {
+			return nil, atField(field, fmt.Errorf("pattern %d has no regex", i))
+		}
+
+		// Check the regex as written so errors quote what the author typed
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return nil, atField(field+".regex", fmt.Errorf("invalid regex '%s': %w", p.Regex, err))
+		}
+		if !caseSensitive {
+			re = regexp.MustCompile("(?i)" + p.Regex)
+		}
+		// A pattern that matches nothing at all would accept (or reject) every answer
+		if re.MatchString("") {
+			return nil, atField(field+".regex", fmt.Errorf("regex '%s' matches an empty answer", p.Regex))
+		}
+
+		name := p.Name
+		if name == "" {
+			name = p.Regex
+		}
+		rules[i] = game.PatternRule{Name: name, Pattern: re, Forbidden: p.Forbidden}
+	}
+	return rules, nil
+}
+
+// validateFuzzy checks the edit distance settings of a validation block
+func validateFuzzy(v *YAMLValidation) error {
+	if !v.Fuzzy && (v.MaxDistance != 0 || len(v.Distances) > 0) {
+		return atField("validation.fuzzy", fmt.Errorf("max_distance and distances require fuzzy: true"))
+	}
+	if v.MaxDistance < 0 {
+		return atField("validation.max_distance", fmt.Errorf("max_distance must not be negative"))
+	}
+
+	// Distances may be set per alternative or for a whole group
+	listed := make(map[string]bool, len(v.Keywords))
+	for _, kw := range v.Keywords {
+		listed[kw.label()] = true
+		for _, alt := range kw.Any {
+			listed[alt] = true
+		}
+	}
+	keywords := make([]string, 0, len(v.Distances))
+	for kw := range v.Distances {
+		keywords = append(keywords, kw)
+	}
+	sort.Strings(keywords)
+	for _, kw := range keywords {
+		field := "validation.distances." + kw
+		if !listed[kw] {
+			return atField(field, fmt.Errorf("distance set for '%s', which is not a keyword", kw))
+		}
+		if v.Distances[kw] < 0 {
+			return atField(field, fmt.Errorf("distance for '%s' must not be negative", kw))
+		}
 	}
+	return nil
+}
+
+// convertYAMLNext splits a YAML 'next' into its default target and conditional branches
+// The default is the single branch without a 'when', which must come last
+func convertYAMLNext(field string, yamlNext YAMLNext) (string, []Branch, error) {
+	if len(yamlNext.Branches) == 0 {
+		return yamlNext.Target, nil, nil
+	}
+
+	var branches []Branch
+	def := ""
+	for i, b := range yamlNext.Branches {
+		branchField := fmt.Sprintf("%s[%d]", field, i)
+		if def != "" {
+			return "", nil, atField(fmt.Sprintf("%s[%d]", field, i-1), fmt.Errorf("the default branch (no 'when') must be last"))
+		}
+		if b.When == "" {
+			if b.Next == "" {
+				return "", nil, atField(branchField, fmt.Errorf("default branch is missing 'next'"))
+			}
+			def = b.Next
+			continue
+		}
 
-	return scene, nil
+		when, err := condition.Parse(b.When)
+		if err != nil {
+			return "", nil, atField(branchField+".when", fmt.Errorf("invalid when '%s': %w", b.When, err))
+		}
+		branches = append(branches, Branch{When: when, Next: b.Next})
+	}
+
+	if def == "" {
+		return "", nil, atField(field, fmt.Errorf("branching next requires a default branch (an entry with 'next' but no 'when')"))
+	}
+	return def, branches, nil
 }
 
 // validateSceneID validates the scene ID format: chapter.scene-number:description
@@ -131,52 +836,110 @@ func validateSceneID(id string) error {
 }
 
 // validateSceneGraph validates the scene graph structure
-func validateSceneGraph(scenes []Scene, sceneMap map[string]*Scene) error {
-	errors := []string{}
+func validateSceneGraph(scenes []Scene) Diagnostics {
+	var diags Diagnostics
+
+	// Index scenes by ID, reporting any ID defined more than once
+	sceneMap := make(map[string]*Scene, len(scenes))
+	for i := range scenes {
+		scene := &scenes[i]
+		if first, exists := sceneMap[scene.ID]; exists {
+			diags = append(diags, diagnose(scene, "id", fmt.Sprintf("duplicate scene ID (first defined at %s)", first.Pos)))
+			continue
+		}
+		sceneMap[scene.ID] = scene
+	}
 
+	// Collect every concept a scene can teach, for checking knows()
+	taught := make(map[string]bool)
 	for _, scene := range scenes {
+		for _, concept := range scene.Teaches {
+			taught[concept] = true
+		}
+	}
+
+	for i := range scenes {
+		scene := &scenes[i]
+
 		// Check thread type
 		switch scene.ThreadType {
 		case ThreadMulti:
 			// Multi must have choices
 			if len(scene.Choices) == 0 {
-				errors = append(errors, fmt.Sprintf("scene %s: thread_type 'multi' requires at least one choice", scene.ID))
+				diags = append(diags, diagnose(scene, "choices", "thread_type 'multi' requires at least one choice"))
+			} else if !slices.ContainsFunc(scene.Choices, func(c Choice) bool { return !c.Gated() }) {
+				// A player who meets none of the conditions would have nothing to pick
+				diags = append(diags, diagnose(scene, "choices", "thread_type 'multi' requires at least one choice without 'requires'"))
 			}
 			// Each choice must have a valid 'next'
 			for i, choice := range scene.Choices {
-				if err := validateNext(choice.Next, scene.ID, sceneMap); err != nil {
-					errors = append(errors, fmt.Sprintf("scene %s choice %d: %v", scene.ID, i, err))
-				}
+				diags = append(diags, validateRoutes(scene, fmt.Sprintf("choices[%d].next", i), choice.Branches, choice.Next, sceneMap, taught)...)
 			}
 
-		case ThreadOpen, ThreadAffirmative, ThreadFinisher:
-			// These must have scene-level 'next'
+		case ThreadMany:
+			if len(scene.Choices) < 2 {
+				diags = append(diags, diagnose(scene, "choices", "thread_type 'many' requires at least two choices"))
+			}
 			if scene.Next == "" {
-				errors = append(errors, fmt.Sprintf("scene %s: thread_type '%s' requires 'next' field at scene level", scene.ID, scene.ThreadType))
+				diags = append(diags, diagnose(scene, "next", "thread_type 'many' requires 'next' field at scene level"))
+				break
 			}
-			if err := validateNext(scene.Next, scene.ID, sceneMap); err != nil {
-				errors = append(errors, fmt.Sprintf("scene %s: %v", scene.ID, err))
+			diags = append(diags, validateRoutes(scene, "next", scene.Branches, scene.Next, sceneMap, taught)...)
+
+		case ThreadOpen, ThreadAffirmative, ThreadFinisher, ThreadAbility:
+			// These must have scene-level 'next'
+			if scene.Next == "" {
+				diags = append(diags, diagnose(scene, "next", fmt.Sprintf("thread_type '%s' requires 'next' field at scene level", scene.ThreadType)))
+				break
 			}
+			diags = append(diags, validateRoutes(scene, "next", scene.Branches, scene.Next, sceneMap, taught)...)
 
 		default:
-			errors = append(errors, fmt.Sprintf("scene %s: invalid thread_type '%s' (must be multi, open, affirmative, or finisher)", scene.ID, scene.ThreadType))
+			diags = append(diags, diagnose(scene, "thread_type", fmt.Sprintf("invalid thread_type '%s' (must be multi, many, open, affirmative, finisher, or ability)", scene.ThreadType)))
 		}
 
 		// Validate impact format if present
 		for i, choice := range scene.Choices {
 			if choice.Impact != "" {
 				if err := validateImpact(choice.Impact); err != nil {
-					errors = append(errors, fmt.Sprintf("scene %s choice %d: invalid impact format: %v", scene.ID, i, err))
+					diags = append(diags, diagnose(scene, fmt.Sprintf("choices[%d].impact", i), fmt.Sprintf("invalid impact format: %v", err)))
+				}
+			}
+
+			// Validate the scenes and concepts a condition refers to
+			if choice.Requires != nil {
+				if err := validateCondition(choice.Requires, sceneMap, taught); err != nil {
+					diags = append(diags, diagnose(scene, fmt.Sprintf("choices[%d].requires", i), err.Error()))
 				}
 			}
 		}
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("\n  - %s", strings.Join(errors, "\n  - "))
+	return diags
+}
+
+// validateRoutes checks every target and condition of a 'next' field
+// Branch i is at field[i]; the default follows the branches
+func validateRoutes(scene *Scene, field string, branches []Branch, def string, sceneMap map[string]*Scene, taught map[string]bool) Diagnostics {
+	var diags Diagnostics
+	for i, b := range branches {
+		branchField := fmt.Sprintf("%s[%d]", field, i)
+		if err := validateNext(b.Next, scene.ID, sceneMap); err != nil {
+			diags = append(diags, diagnose(scene, branchField+".next", err.Error()))
+		}
+		if err := validateCondition(b.When, sceneMap, taught); err != nil {
+			diags = append(diags, diagnose(scene, branchField+".when", err.Error()))
+		}
 	}
 
-	return nil
+	defField := field
+	if len(branches) > 0 {
+		defField = fmt.Sprintf("%s[%d].next", field, len(branches))
+	}
+	if err := validateNext(def, scene.ID, sceneMap); err != nil {
+		diags = append(diags, diagnose(scene, defField, err.Error()))
+	}
+	return diags
 }
 
 // validateNext validates that a 'next' value is valid
@@ -193,23 +956,35 @@ func validateNext(next string, currentSceneID string, sceneMap map[string]*Scene
 
 	// Check if the scene exists
 	if _, exists := sceneMap[next]; !exists {
-		return fmt.Errorf("next '%s' references non-existent scene", next)
+		return fmt.Errorf("'%s' references non-existent scene", next)
 	}
 
 	return nil
 }
 
-// validateImpact validates impact string format: entity.attribute±value
-func validateImpact(impact string) error {
-	// Pattern: entity.attribute(.subattribute)*±value
-	// Examples: player.strength+2, npc.teacher.trust-5, world.chaos+10
-	pattern := `^[a-z_]+(\.[a-z_]+)*[+-]\d+$`
-	matched, err := regexp.MatchString(pattern, impact)
-	if err != nil {
-		return fmt.Errorf("regex error: %w", err)
+// validateCondition checks that a condition only references scenes,
+// choices and concepts that exist in the graph
+func validateCondition(expr *condition.Expr, sceneMap map[string]*Scene, taught map[string]bool) error {
+	for _, ref := range expr.Refs() {
+		target, exists := sceneMap[ref.SceneID]
+		if !exists {
+			return fmt.Errorf("references non-existent scene '%s'", ref.SceneID)
+		}
+		if ref.Choice >= 0 && ref.Choice >= len(target.Choices) {
+			return fmt.Errorf("references choice %d of scene '%s', which has %d choice(s)", ref.Choice, ref.SceneID, len(target.Choices))
+		}
 	}
-	if !matched {
-		return fmt.Errorf("'%s' must be format entity.attribute±value (e.g., player.strength+2)", impact)
+	for _, concept := range expr.Concepts() {
+		if !taught[concept] {
+			return fmt.Errorf("knows(%s) can never be true: no scene teaches '%s'", concept, concept)
+		}
 	}
 	return nil
 }
+
+// validateImpact validates impact string format: entity.attribute±value
+func validateImpact(expr string) error {
+	// Examples: player.strength+2, npc.teacher.trust-5, world.chaos+10
+	_, err := impact.Parse(expr)
+	return err
+}
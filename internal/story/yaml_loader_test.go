@@ -1,8 +1,13 @@
 package story
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/jredh-dev/divine-academy/internal/game"
 )
 
 func TestLoadScenesFromYAML(t *testing.T) {
@@ -43,16 +48,15 @@ func TestLoadScenesFromYAML(t *testing.T) {
 	}
 }
 
-func TestGetScene(t *testing.T) {
+func TestSceneRepository_Scene(t *testing.T) {
 	// Load scenes first
 	scenes, err := LoadScenesFromYAML("../../scenes/preface.yaml")
 	if err != nil {
 		t.Fatalf("Failed to load scenes: %v", err)
 	}
-	LoadScenes(scenes)
+	repo := NewStaticRepository(scenes, nil)
 
-	// Test GetScene function
-	scene := GetScene("preface.0:dream-start")
+	scene := repo.Scene("preface.0:dream-start")
 	if scene == nil {
 		t.Fatal("Expected to find 'preface.0:dream-start' scene")
 	}
@@ -62,7 +66,7 @@ func TestGetScene(t *testing.T) {
 	}
 
 	// Test non-existent scene
-	nonExistent := GetScene("does-not-exist")
+	nonExistent := repo.Scene("does-not-exist")
 	if nonExistent != nil {
 		t.Error("Expected nil for non-existent scene")
 	}
@@ -107,7 +111,7 @@ func TestSceneProgression(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to load scenes: %v", err)
 	}
-	LoadScenes(scenes)
+	repo := NewStaticRepository(scenes, nil)
 
 	// Test that all scenes in the progression exist
 	expectedScenes := []string{
@@ -117,11 +121,13 @@ func TestSceneProgression(t *testing.T) {
 		"preface.3:teacher-choice",
 		"preface.4:assigned-teacher",
 		"preface.5:tutorial-multiple",
-		"preface.6:end-of-demo",
+		"preface.6:academy-motto",
+		"preface.7:first-ability",
+		"preface.8:end-of-demo",
 	}
 
 	for _, sceneID := range expectedScenes {
-		scene := GetScene(sceneID)
+		scene := repo.Scene(sceneID)
 		if scene == nil {
 			t.Errorf("Expected scene '%s' to exist", sceneID)
 		}
@@ -165,14 +171,14 @@ func TestTerminalScene(t *testing.T) {
 	// Find end-of-demo scene
 	var endScene *Scene
 	for i := range scenes {
-		if scenes[i].ID == "preface.6:end-of-demo" {
+		if scenes[i].ID == "preface.8:end-of-demo" {
 			endScene = &scenes[i]
 			break
 		}
 	}
 
 	if endScene == nil {
-		t.Fatal("Expected to find 'preface.6:end-of-demo' scene")
+		t.Fatal("Expected to find 'preface.8:end-of-demo' scene")
 	}
 
 	if endScene.Next != "0" {
@@ -200,3 +206,902 @@ func TestImpactFormat(t *testing.T) {
 		}
 	}
 }
+
+// writeScenes writes YAML scene content to a temporary file and returns its path
+func writeScenes(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scenes.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write scenes: %v", err)
+	}
+	return path
+}
+
+func TestChoiceRequires(t *testing.T) {
+	path := writeScenes(t, `
+scenes:
+  - id: test.0:start
+    thread_type: multi
+    text: Start
+    teaches: [division]
+    choices:
+      - text: Always
+        next: "0"
+      - text: "[Insight] Clever"
+        next: "0"
+        requires: player.intelligence >= 3 and knows(division)
+`)
+
+	scenes, err := LoadScenesFromYAML(path)
+	if err != nil {
+		t.Fatalf("Failed to load scenes: %v", err)
+	}
+
+	choices := scenes[0].Choices
+	if choices[0].Gated() {
+		t.Error("Expected first choice to be ungated")
+	}
+	if !choices[1].Gated() {
+		t.Fatal("Expected second choice to be gated")
+	}
+	if choices[1].Requires.String() != "player.intelligence >= 3 and knows(division)" {
+		t.Errorf("Unexpected requires: %q", choices[1].Requires.String())
+	}
+	if len(scenes[0].Teaches) != 1 || scenes[0].Teaches[0] != "division" {
+		t.Errorf("Expected teaches [division], got %v", scenes[0].Teaches)
+	}
+}
+
+func TestChoiceRequiresErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		requires string
+		wantErr  string
+	}{
+		{"syntax error", "player.intelligence >=", "invalid requires"},
+		{"not boolean", "player.intelligence", "invalid requires"},
+		{"missing scene", "visited(test.9:nowhere)", "non-existent scene"},
+		{"choice out of range", "chose(test.0:start, 5)", "has 2 choice(s)"},
+		{"concept never taught", "knows(algebra)", "no scene teaches"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeScenes(t, `
+scenes:
+  - id: test.0:start
+    thread_type: multi
+    text: Start
+    choices:
+      - text: Always
+        next: "0"
+      - text: Gated
+        next: "0"
+        requires: "`+tt.requires+`"
+`)
+			_, err := LoadScenesFromYAML(path)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestChoiceRequires_AllGated(t *testing.T) {
+	path := writeScenes(t, `
+scenes:
+  - id: test.0:start
+    thread_type: multi
+    text: Start
+    choices:
+      - text: Clever
+        next: "0"
+        requires: player.intelligence >= 3
+      - text: Strong
+        next: "0"
+        requires: player.strength >= 3
+`)
+	_, err := LoadScenesFromYAML(path)
+	if err == nil {
+		t.Fatal("Expected error for a multi scene with only gated choices")
+	}
+	if !strings.Contains(err.Error(), "at least one choice without 'requires'") {
+		t.Errorf("Expected ungated choice error, got: %v", err)
+	}
+}
+
+// attrEnv is a condition environment with attribute values only
+type attrEnv map[string]int
+
+func (e attrEnv) Attribute(path string) int            { return e[path] }
+func (e attrEnv) HasVisited(string) bool               { return false }
+func (e attrEnv) Knows(string) bool                    { return false }
+func (e attrEnv) HasChosen(sceneID string, i int) bool { return false }
+func (e attrEnv) KarmaScore() int                      { return e["karma"] }
+func (e attrEnv) HasAbility(id string) bool            { return e["ability."+id] > 0 }
+
+func TestBranchingNext(t *testing.T) {
+	path := writeScenes(t, `
+scenes:
+  - id: test.0:start
+    thread_type: affirmative
+    text: Start
+    next:
+      - when: player.intelligence >= 3
+        next: test.1:clever
+      - when: player.strength >= 3
+        next: test.2:strong
+      - next: test.3:plain
+  - id: test.1:clever
+    thread_type: multi
+    text: Clever
+    choices:
+      - text: Onward
+        next:
+          - when: player.intelligence >= 5
+            next: test.2:strong
+          - next: "0"
+  - id: test.2:strong
+    thread_type: affirmative
+    text: Strong
+    next: 0
+  - id: test.3:plain
+    thread_type: affirmative
+    text: Plain
+    next: 0
+`)
+
+	scenes, err := LoadScenesFromYAML(path)
+	if err != nil {
+		t.Fatalf("Failed to load scenes: %v", err)
+	}
+
+	start := &scenes[0]
+	if start.Next != "test.3:plain" {
+		t.Errorf("Expected default next 'test.3:plain', got '%s'", start.Next)
+	}
+	if len(start.Branches) != 2 {
+		t.Fatalf("Expected 2 branches, got %d", len(start.Branches))
+	}
+
+	tests := []struct {
+		env  attrEnv
+		want string
+	}{
+		{attrEnv{"player.intelligence": 3}, "test.1:clever"},
+		{attrEnv{"player.intelligence": 3, "player.strength": 3}, "test.1:clever"},
+		{attrEnv{"player.strength": 4}, "test.2:strong"},
+		{attrEnv{}, "test.3:plain"},
+	}
+	for _, tt := range tests {
+		if got := start.NextFor(tt.env); got != tt.want {
+			t.Errorf("NextFor(%v) = %s, want %s", tt.env, got, tt.want)
+		}
+	}
+
+	choice := &scenes[1].Choices[0]
+	if got := choice.NextFor(attrEnv{"player.intelligence": 5}); got != "test.2:strong" {
+		t.Errorf("choice NextFor = %s, want test.2:strong", got)
+	}
+	if got := choice.NextFor(attrEnv{}); got != "0" {
+		t.Errorf("choice NextFor = %s, want 0", got)
+	}
+
+	targets := start.Targets()
+	if len(targets) != 3 || targets[2] != "test.3:plain" {
+		t.Errorf("Targets() = %v", targets)
+	}
+
+	// Scalar next has no branches
+	if len(scenes[2].Branches) != 0 || scenes[2].Next != "0" {
+		t.Errorf("Expected scalar next '0', got %q with %d branches", scenes[2].Next, len(scenes[2].Branches))
+	}
+}
+
+func TestBranchingNextErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		next    string
+		wantErr string
+	}{
+		{
+			name: "missing default",
+			next: `
+      - when: player.strength >= 1
+        next: test.1:end`,
+			wantErr: "requires a default branch",
+		},
+		{
+			name: "default not last",
+			next: `
+      - next: test.1:end
+      - when: player.strength >= 1
+        next: test.1:end`,
+			wantErr: "must be last",
+		},
+		{
+			name: "invalid condition",
+			next: `
+      - when: player.strength
+        next: test.1:end
+      - next: test.1:end`,
+			wantErr: "invalid when",
+		},
+		{
+			name: "missing branch target",
+			next: `
+      - when: player.strength >= 1
+        next: test.9:nowhere
+      - next: test.1:end`,
+			wantErr: "non-existent scene",
+		},
+		{
+			name: "missing default target",
+			next: `
+      - when: player.strength >= 1
+        next: test.1:end
+      - next: test.9:nowhere`,
+			wantErr: "non-existent scene",
+		},
+		{
+			name: "condition references missing scene",
+			next: `
+      - when: visited(test.9:nowhere)
+        next: test.1:end
+      - next: test.1:end`,
+			wantErr: "non-existent scene",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeScenes(t, `
+scenes:
+  - id: test.0:start
+    thread_type: affirmative
+    text: Start
+    next:`+tt.next+`
+  - id: test.1:end
+    thread_type: affirmative
+    text: End
+    next: 0
+`)
+			_, err := LoadScenesFromYAML(path)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestOpenResponseValidator(t *testing.T) {
+	scenes, err := LoadScenesFromYAML("../../scenes/preface.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load scenes: %v", err)
+	}
+	scene := NewStaticRepository(scenes, nil).Scene("preface.3:teacher-choice")
+	if scene.Validator == nil {
+		t.Fatal("Expected teacher-choice to have a validator")
+	}
+	if scene.Weighted() {
+		t.Error("Expected teacher-choice, an opinion, not to count toward the grade")
+	}
+
+	if !scene.Validator.Validate("I'd like Professor Sera's class").Correct {
+		t.Error("Expected answer naming Sera to be accepted")
+	}
+	if scene.Validator.Validate("Whoever is nicer").Correct {
+		t.Error("Expected answer naming neither teacher to be rejected")
+	}
+}
+
+func TestValidationBlock(t *testing.T) {
+	tests := []struct {
+		name       string
+		validation string
+		input      string
+		wantOK     bool
+	}{
+		{
+			name:       "keywords with required count",
+			validation: "keywords: [france, britain, russia]\n      required_count: 2",
+			input:      "France and Britain",
+			wantOK:     true,
+		},
+		{
+			name:       "required count not met",
+			validation: "keywords: [france, britain, russia]\n      required_count: 2",
+			input:      "Only France",
+			wantOK:     false,
+		},
+		{
+			name:       "case sensitive",
+			validation: "keywords: [WWI]\n      case_sensitive: true",
+			input:      "wwi",
+			wantOK:     false,
+		},
+		{
+			name:       "partial match",
+			validation: "keywords: [divi]\n      partial: true",
+			input:      "division",
+			wantOK:     true,
+		},
+		{
+			name:       "fuzzy match",
+			validation: "keywords: [versailles]\n      fuzzy: true",
+			input:      "Versaille",
+			wantOK:     true,
+		},
+		{
+			name:       "fuzzy per-keyword distance",
+			validation: "keywords: [versailles]\n      fuzzy: true\n      distances:\n        versailles: 0",
+			input:      "Versaille",
+			wantOK:     false,
+		},
+		{
+			name:       "fuzzy max distance",
+			validation: "keywords: [division]\n      fuzzy: true\n      max_distance: 3",
+			input:      "divsn",
+			wantOK:     true,
+		},
+		{
+			name:       "keyword group alternatives",
+			validation: "keywords:\n        - [WWI, World War One, the Great War]\n        - name: treaty\n          any: [Versailles, peace treaty]\n      required_count: 2",
+			input:      "The Great War ended with a peace treaty",
+			wantOK:     true,
+		},
+		{
+			name:       "alternatives count once",
+			validation: "keywords:\n        - [WWI, World War One]\n        - Versailles\n      required_count: 2",
+			input:      "WWI or World War One",
+			wantOK:     false,
+		},
+		{
+			name:       "stemming",
+			validation: "keywords: [divide]\n      stem: true",
+			input:      "We divided it",
+			wantOK:     true,
+		},
+		{
+			name:       "forbidden keyword",
+			validation: "keywords: [war]\n      forbidden: [WWII]",
+			input:      "The war was WWII",
+			wantOK:     false,
+		},
+		{
+			name:       "forbidden only",
+			validation: "forbidden: [idk]",
+			input:      "I think it was 1914",
+			wantOK:     true,
+		},
+		{
+			name:       "required pattern",
+			validation: "patterns:\n        - name: the equation\n          regex: 'x\\s*=\\s*4'",
+			input:      "X =4",
+			wantOK:     true,
+		},
+		{
+			name:       "case sensitive pattern",
+			validation: "patterns:\n        - regex: 'x\\s*=\\s*4'\n      case_sensitive: true",
+			input:      "X = 4",
+			wantOK:     false,
+		},
+		{
+			name:       "forbidden pattern",
+			validation: "keywords: [1914]\n      patterns:\n        - regex: '\\?'\n          forbidden: true",
+			input:      "1914?",
+			wantOK:     false,
+		},
+		{
+			name:       "numeric with tolerance",
+			validation: "numeric:\n        answers: [3.14]\n        tolerance: 0.01",
+			input:      "3.15",
+			wantOK:     true,
+		},
+		{
+			name:       "numeric fraction",
+			validation: "numeric:\n        answers: [0.75]",
+			input:      "3/4",
+			wantOK:     true,
+		},
+		{
+			name:       "numeric unit conversion",
+			validation: "numeric:\n        answers: [12]\n        unit: cm\n        require_unit: true",
+			input:      "0.12 m",
+			wantOK:     true,
+		},
+		{
+			name:       "numeric range",
+			validation: "numeric:\n        ranges:\n          - {min: 10, max: 12}",
+			input:      "11",
+			wantOK:     true,
+		},
+		{
+			name:       "numeric relative tolerance",
+			validation: "numeric:\n        answers: [200]\n        relative_tolerance: 0.1",
+			input:      "215",
+			wantOK:     true,
+		},
+		{
+			name:       "numeric wrong",
+			validation: "numeric:\n        answers: [1914]",
+			input:      "1918",
+			wantOK:     false,
+		},
+		{
+			name:       "equivalent expression",
+			validation: "expression:\n        answer: 2(x+3)",
+			input:      "6 + 2x",
+			wantOK:     true,
+		},
+		{
+			name:       "expression with a slip",
+			validation: "expression:\n        answer: 2(x+3)",
+			input:      "2x + 5",
+			wantOK:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeScenes(t, `
+scenes:
+  - id: test.0:question
+    thread_type: open
+    text: Question
+    validation:
+      `+tt.validation+`
+    next: 0
+`)
+			scenes, err := LoadScenesFromYAML(path)
+			if err != nil {
+				t.Fatalf("Failed to load scenes: %v", err)
+			}
+			v := scenes[0].Validator
+			if v == nil {
+				t.Fatal("Expected a validator")
+			}
+			if got := v.Validate(tt.input).Correct; got != tt.wantOK {
+				t.Errorf("Validate(%q).Correct = %v, want %v", tt.input, got, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestExpressionSlipScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		slipScore string
+		want      float64
+	}{
+		{"default", "", game.DefaultSlipScore},
+		{"custom", "\n        slip_score: 0.25", 0.25},
+		{"off", "\n        slip_score: 0", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeScenes(t, `
+scenes:
+  - id: test.0:question
+    thread_type: open
+    text: Question
+    validation:
+      expression:
+        answer: 2(x+3)`+tt.slipScore+`
+    next: 0
+`)
+			scenes, err := LoadScenesFromYAML(path)
+			if err != nil {
+				t.Fatalf("Failed to load scenes: %v", err)
+			}
+			if got := scenes[0].Validator.Validate("2x + 5").Score; got != tt.want {
+				t.Errorf("slip score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidationBlockErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		validation string
+		wantErr    string
+	}{
+		{"required count too high", "keywords: [a, b]\n      required_count: 3", "required_count 3"},
+		{"required count without keywords", "required_count: 1", "no keywords"},
+		{"keywords and numeric", "keywords: [a]\n      numeric:\n        answers: [1]", "not both"},
+		{"numeric without answers", "numeric:\n        tolerance: 1", "at least one answer"},
+		{"negative tolerance", "numeric:\n        answers: [1]\n        tolerance: -1", "tolerance"},
+		{"empty keyword", "keywords: [\"\"]", "keyword 0 is empty"},
+		{"empty alternative", "keywords:\n        - [a, \"\"]", "keyword 0 alternative 1 is empty"},
+		{"group without alternatives", "keywords:\n        - name: empty", "keyword 0 has no alternatives"},
+		{"empty forbidden", "keywords: [a]\n      forbidden: [\"\"]", "forbidden keyword 0 is empty"},
+		{"stem without keywords", "stem: true", "stem is set but no keywords"},
+		{"fuzzy distance for group", "keywords:\n        - name: ww1\n          any: [WWI]\n      fuzzy: true\n      distances:\n        ww2: 1", "distance set for 'ww2'"},
+		{"invalid regex", "patterns:\n        - regex: 'x('", "patterns[0].regex: invalid regex 'x('"},
+		{"regex matches empty answer", "patterns:\n        - regex: 'x*'", "matches an empty answer"},
+		{"pattern without regex", "patterns:\n        - name: nothing", "pattern 0 has no regex"},
+		{"patterns and numeric", "patterns:\n        - regex: x\n      numeric:\n        answers: [1]", "not both"},
+		{"forbidden and numeric", "forbidden: [a]\n      numeric:\n        answers: [1]", "validation.forbidden: forbidden only applies to keywords and patterns, not numeric"},
+		{"fuzzy and numeric", "fuzzy: true\n      numeric:\n        answers: [1]", "validation.fuzzy: fuzzy only applies"},
+		{"stem and numeric", "stem: true\n      numeric:\n        answers: [1]", "validation.stem: stem only applies"},
+		{"require unit without unit", "numeric:\n        answers: [1]\n        require_unit: true", "no unit is given"},
+		{"inverted range", "numeric:\n        ranges:\n          - {min: 5, max: 1}", "ranges[0]: range min 5 is greater than max 1"},
+		{"relative tolerance too large", "numeric:\n        answers: [1]\n        relative_tolerance: 1", "relative_tolerance"},
+		{"distance without fuzzy", "keywords: [a]\n      max_distance: 1", "require fuzzy: true"},
+		{"negative max distance", "keywords: [a]\n      fuzzy: true\n      max_distance: -1", "max_distance must not be negative"},
+		{"expression and keywords", "keywords: [a]\n      expression:\n        answer: x", "cannot be combined"},
+		{"fuzzy and expression", "fuzzy: true\n      expression:\n        answer: x", "validation.fuzzy: fuzzy only applies to keywords and patterns, not expression"},
+		{"expression without answer", "expression:\n        slip_score: 0.5", "expression requires an answer"},
+		{"invalid expression", "expression:\n        answer: 2(x+", "expression.answer: invalid expression answer"},
+		{"slip score out of range", "expression:\n        answer: x\n        slip_score: 2", "slip_score must be between 0 and 1"},
+		{"distance for unknown keyword", "keywords: [a]\n      fuzzy: true\n      distances:\n        b: 1", "distances.b: distance set for 'b'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeScenes(t, `
+scenes:
+  - id: test.0:question
+    thread_type: open
+    text: Question
+    validation:
+      `+tt.validation+`
+    next: 0
+`)
+			_, err := LoadScenesFromYAML(path)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestFinisherTarget(t *testing.T) {
+	path := writeScenes(t, `
+scenes:
+  - id: test.0:recite
+    thread_type: finisher
+    text: Finish the motto
+    target: "  Knowledge is power  "
+    next: 0
+`)
+	scenes, err := LoadScenesFromYAML(path)
+	if err != nil {
+		t.Fatalf("Failed to load scenes: %v", err)
+	}
+	finisher := scenes[0].Finisher
+	if finisher == nil {
+		t.Fatal("Expected finisher scene to have a target")
+	}
+	if finisher.Target != "Knowledge is power" {
+		t.Errorf("Target = %q, want trimmed passage", finisher.Target)
+	}
+	if !finisher.Check("knowledge is power").Complete {
+		t.Error("Expected target passage to complete the scene")
+	}
+}
+
+func TestFinisherTargetErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		scene   string
+		wantErr string
+	}{
+		{"finisher without target", "thread_type: finisher", "target: finisher scenes need a target passage"},
+		{"target on affirmative", "thread_type: affirmative\n    target: Hello", "target is only supported on thread_type 'finisher'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeScenes(t, `
+scenes:
+  - id: test.0:recite
+    text: Recite
+    `+tt.scene+`
+    next: 0
+`)
+			_, err := LoadScenesFromYAML(path)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestManyChoiceScene(t *testing.T) {
+	path := writeScenes(t, `
+scenes:
+  - id: test.0:primes
+    thread_type: many
+    text: Which are prime?
+    choices:
+      - text: "2"
+        correct: true
+      - text: "4"
+      - text: "7"
+        correct: true
+        impact: player.knowledge+1
+    next: 0
+`)
+	scenes, err := LoadScenesFromYAML(path)
+	if err != nil {
+		t.Fatalf("Failed to load scenes: %v", err)
+	}
+	selection := scenes[0].Selection
+	if selection == nil {
+		t.Fatal("Expected many-choice scene to have a selection validator")
+	}
+	if !slices.Equal(selection.CorrectIndices, []int{0, 2}) {
+		t.Errorf("CorrectIndices = %v, want [0 2]", selection.CorrectIndices)
+	}
+	if got := selection.ValidateChoice([]int{0}).Score; got != 0.5 {
+		t.Errorf("Score for one of two answers = %v, want 0.5", got)
+	}
+}
+
+func TestManyChoiceSceneErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		scene   string
+		wantErr string
+	}{
+		{"no correct choice", "thread_type: many\n    choices:\n      - text: a\n      - text: b\n    next: 0", "needs at least one choice marked correct"},
+		{"one choice", "thread_type: many\n    choices:\n      - text: a\n        correct: true\n    next: 0", "requires at least two choices"},
+		{"without next", "thread_type: many\n    choices:\n      - text: a\n        correct: true\n      - text: b", "thread_type 'many' requires 'next'"},
+		{"choice with next", "thread_type: many\n    choices:\n      - text: a\n        correct: true\n        next: 0\n      - text: b\n    next: 0", "choices[0].next: choices on thread_type 'many' cannot have next"},
+		{"choice with requires", "thread_type: many\n    choices:\n      - text: a\n        correct: true\n      - text: b\n        requires: player.wit >= 1\n    next: 0", "choices[1].requires: choices on thread_type 'many' cannot have requires"},
+		{"correct on multi", "thread_type: multi\n    choices:\n      - text: a\n        correct: true\n        next: 0", "choices[0].correct: correct is only supported on thread_type 'many'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeScenes(t, `
+scenes:
+  - id: test.0:quiz
+    text: Quiz
+    `+tt.scene+`
+`)
+			_, err := LoadScenesFromYAML(path)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSceneWeight(t *testing.T) {
+	path := writeScenes(t, `
+scenes:
+  - id: test.0:question
+    thread_type: open
+    text: Question
+    validation:
+      keywords: [answer]
+    next: test.1:heavy
+  - id: test.1:heavy
+    thread_type: finisher
+    text: Recite
+    target: Knowledge is power
+    weight: 2.5
+    next: test.2:opinion
+  - id: test.2:opinion
+    thread_type: open
+    text: Opinion
+    validation:
+      keywords: [answer]
+    weight: 0
+    next: test.3:story
+  - id: test.3:story
+    thread_type: affirmative
+    text: Story
+    next: 0
+`)
+	scenes, err := LoadScenesFromYAML(path)
+	if err != nil {
+		t.Fatalf("Failed to load scenes: %v", err)
+	}
+
+	want := []struct {
+		graded, weighted bool
+		weight           float64
+	}{{true, true, 1}, {true, true, 2.5}, {true, false, 0}, {false, false, 0}}
+	for i, w := range want {
+		if got := scenes[i].Graded(); got != w.graded {
+			t.Errorf("%s: Graded() = %v, want %v", scenes[i].ID, got, w.graded)
+		}
+		if got := scenes[i].Weighted(); got != w.weighted {
+			t.Errorf("%s: Weighted() = %v, want %v", scenes[i].ID, got, w.weighted)
+		}
+		if got := scenes[i].Weight; got != w.weight {
+			t.Errorf("%s: Weight = %v, want %v", scenes[i].ID, got, w.weight)
+		}
+	}
+}
+
+func TestSceneWeightErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		scene   string
+		wantErr string
+	}{
+		{"weight on ungraded scene", "thread_type: affirmative\n    weight: 2", "weight: weight is only supported on graded scenes"},
+		{"negative weight", "thread_type: finisher\n    target: Hello\n    weight: -1", "weight: weight must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeScenes(t, `
+scenes:
+  - id: test.0:scene
+    text: Scene
+    `+tt.scene+`
+    next: 0
+`)
+			_, err := LoadScenesFromYAML(path)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSceneHints(t *testing.T) {
+	path := writeScenes(t, `
+scenes:
+  - id: test.0:question
+    thread_type: open
+    text: Question
+    hints:
+      - "  Think about who greeted you.  "
+      - Her name starts with S.
+    next: 0
+`)
+	scenes, err := LoadScenesFromYAML(path)
+	if err != nil {
+		t.Fatalf("Failed to load scenes: %v", err)
+	}
+	want := []string{"Think about who greeted you.", "Her name starts with S."}
+	if !slices.Equal(scenes[0].Hints, want) {
+		t.Errorf("Hints = %q, want %q", scenes[0].Hints, want)
+	}
+}
+
+func TestSceneHintsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		scene   string
+		wantErr string
+	}{
+		{"empty hint", "thread_type: open\n    hints: [Look closer, \"\"]", "hints[1]: hint 1 is empty"},
+		{"hints on affirmative", "thread_type: affirmative\n    hints: [Just click]", "hints: hints are not supported on thread_type 'affirmative'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeScenes(t, `
+scenes:
+  - id: test.0:scene
+    text: Scene
+    `+tt.scene+`
+    next: 0
+`)
+			_, err := LoadScenesFromYAML(path)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestChoiceApproach(t *testing.T) {
+	path := writeScenes(t, `
+scenes:
+  - id: test.0:shopkeeper
+    thread_type: multi
+    text: The shopkeeper flinches when the boss walks in.
+    choices:
+      - text: My boss is awful too.
+        approach: empathize
+        next: test.1:ending
+      - text: This place is awful.
+        approach: Relate
+        next: test.1:ending
+      - text: Bat your eyelashes.
+        approach: seduce
+        next: test.1:ending
+  - id: test.1:ending
+    thread_type: affirmative
+    text: Later that night...
+    next:
+      - when: karma(empathic)
+        next: test.2:kind
+      - when: karma_at_most(-1)
+        next: test.2:cruel
+      - next: test.2:plain
+  - id: test.2:kind
+    thread_type: affirmative
+    text: Kind
+    next: 0
+  - id: test.2:cruel
+    thread_type: affirmative
+    text: Cruel
+    next: 0
+  - id: test.2:plain
+    thread_type: affirmative
+    text: Plain
+    next: 0
+`)
+	scenes, err := LoadScenesFromYAML(path)
+	if err != nil {
+		t.Fatalf("Failed to load scenes: %v", err)
+	}
+
+	var got []game.Approach
+	for _, choice := range scenes[0].Choices {
+		got = append(got, choice.Approach)
+	}
+	want := []game.Approach{game.ApproachEmpathize, game.ApproachRelate, game.ApproachCoerce}
+	if !slices.Equal(got, want) {
+		t.Errorf("Approaches = %v, want %v", got, want)
+	}
+
+	ending := scenes[1]
+	for karma, next := range map[int]string{2: "test.2:kind", 0: "test.2:plain", -1: "test.2:cruel"} {
+		if got := ending.NextFor(attrEnv{"karma": karma}); got != next {
+			t.Errorf("karma %d: NextFor = %s, want %s", karma, got, next)
+		}
+	}
+}
+
+func TestChoiceApproachErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		scene   string
+		wantErr string
+	}{
+		{"unknown approach", "thread_type: multi\n    choices:\n      - text: a\n        approach: bribe\n        next: 0", "choices[0].approach: unknown approach 'bribe'"},
+		{"approach on many", "thread_type: many\n    choices:\n      - text: a\n        correct: true\n        approach: relate\n      - text: b\n    next: 0", "choices[0].approach: approach is only supported on thread_type 'multi'"},
+		{"bad karma condition", "thread_type: multi\n    choices:\n      - text: a\n        requires: karma(evil)\n        next: 0", "must be empathic, neutral, or coercive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeScenes(t, `
+scenes:
+  - id: test.0:scene
+    text: Scene
+    `+tt.scene+`
+`)
+			_, err := LoadScenesFromYAML(path)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Live feedback for finisher scenes.
+// Sends the passage to the server as the player types and shows which words
+// are right so far. The form still works without this script.
+(function () {
+    'use strict';
+
+    var form = document.querySelector('.finisher-form');
+    if (!form || !window.fetch) {
+        return;
+    }
+
+    var input = form.querySelector('textarea[name="user_text"]');
+    var progress = form.querySelector('.finisher-progress');
+    var url = form.getAttribute('data-check-url');
+    var timer = null;
+    var pending = null;
+
+    function render(completion) {
+        progress.textContent = '';
+        completion.tokens.forEach(function (token, i) {
+            if (i > 0) {
+                progress.appendChild(document.createTextNode(' '));
+            }
+            var span = document.createElement('span');
+            span.className = 'token token-' + token.status;
+            span.textContent = token.text;
+            progress.appendChild(span);
+        });
+
+        if (completion.remaining > 0) {
+            var left = document.createElement('span');
+            left.className = 'token-remaining';
+            left.textContent = ' (' + completion.remaining + ' more word' + (completion.remaining === 1 ? '' : 's') + ')';
+            progress.appendChild(left);
+        }
+        progress.hidden = completion.tokens.length === 0;
+
+        // Advance as soon as the passage is complete
+        if (completion.complete) {
+            form.submit();
+        }
+    }
+
+    function check() {
+        if (pending) {
+            pending.abort();
+        }
+        pending = new AbortController();
+
+        fetch(url, {
+            method: 'POST',
+            body: new URLSearchParams(new FormData(form)),
+            credentials: 'same-origin',
+            signal: pending.signal
+        })
+            .then(function (response) {
+                if (!response.ok) {
+                    throw new Error(response.statusText);
+                }
+                return response.json();
+            })
+            .then(render)
+            .catch(function () {
+                // Live feedback is optional; submitting the form still works
+            });
+    }
+
+    input.addEventListener('input', function () {
+        clearTimeout(timer);
+        timer = setTimeout(check, 150);
+    });
+})();
@@ -0,0 +1,76 @@
+// Genie in a bottle.
+// Rubbing the bottle fetches the next hint tier and shows it without a page
+// reload, with a shake while the genie answers. While the genie rests, the
+// status counts down and the bottle glows again once it is ready.
+// The form still works without this script.
+(function () {
+    'use strict';
+
+    var bottle = document.querySelector('.genie');
+    if (!bottle || !window.fetch) {
+        return;
+    }
+
+    var form = bottle.querySelector('.genie-form');
+    var button = form.querySelector('.genie-bottle');
+    var list = bottle.querySelector('.genie-hints');
+    var status = bottle.querySelector('.genie-status');
+    var url = bottle.getAttribute('data-hint-url');
+    var timer = null;
+
+    function render(view) {
+        list.textContent = '';
+        view.hints.forEach(function (hint) {
+            var item = document.createElement('li');
+            item.textContent = hint;
+            list.appendChild(item);
+        });
+        status.textContent = view.status;
+        button.disabled = !view.ready;
+        bottle.classList.toggle('genie-ready', view.ready);
+        countdown(view.remaining > 0 ? view.wait : 0);
+    }
+
+    // Re-enable the bottle when the genie has rested
+    function countdown(seconds) {
+        clearTimeout(timer);
+        if (seconds <= 0) {
+            return;
+        }
+        timer = setTimeout(function () {
+            button.disabled = false;
+            bottle.classList.add('genie-ready');
+            status.textContent = 'The genie is ready.';
+        }, seconds * 1000);
+    }
+
+    form.addEventListener('submit', function (event) {
+        event.preventDefault();
+        button.disabled = true;
+        bottle.classList.add('genie-rubbing');
+
+        fetch(url, {
+            method: 'POST',
+            body: new URLSearchParams(new FormData(form)),
+            headers: { 'Accept': 'application/json' },
+            credentials: 'same-origin'
+        })
+            .then(function (response) {
+                // Resting (429) and exhausted (409) still describe the bottle
+                if (!response.ok && response.status !== 429 && response.status !== 409) {
+                    throw new Error(response.statusText);
+                }
+                return response.json();
+            })
+            .then(render)
+            .catch(function () {
+                // Fall back to a normal form post
+                form.submit();
+            })
+            .finally(function () {
+                bottle.classList.remove('genie-rubbing');
+            });
+    });
+
+    countdown(parseInt(bottle.getAttribute('data-wait'), 10) || 0);
+})();
@@ -0,0 +1,58 @@
+// Live feedback for open responses.
+// As the player types, the textarea glows brighter the closer the draft is to
+// an accepted answer. Only the score is used, so missing keywords stay hidden.
+// The form still works without this script.
+(function () {
+    'use strict';
+
+    var form = document.querySelector('.open-form');
+    if (!form || !window.fetch) {
+        return;
+    }
+
+    var input = form.querySelector('textarea[name="user_text"]');
+    var url = form.getAttribute('data-validate-url');
+    var timer = null;
+    var pending = null;
+
+    function update(feedback) {
+        var glow = feedback.correct ? 1 : Math.max(0, Math.min(1, feedback.score));
+        input.style.setProperty('--glow', glow.toFixed(2));
+        input.classList.toggle('glow-correct', feedback.correct);
+    }
+
+    function check() {
+        if (pending) {
+            pending.abort();
+        }
+        pending = new AbortController();
+
+        fetch(url, {
+            method: 'POST',
+            body: new URLSearchParams(new FormData(form)),
+            credentials: 'same-origin',
+            signal: pending.signal
+        })
+            .then(function (response) {
+                if (!response.ok) {
+                    throw new Error(response.statusText);
+                }
+                return response.json();
+            })
+            .then(update)
+            .catch(function () {
+                // Live feedback is optional; submitting the form still works
+            });
+    }
+
+    input.classList.add('glow');
+    input.addEventListener('input', function () {
+        clearTimeout(timer);
+        timer = setTimeout(check, 250);
+    });
+
+    // Restored drafts (after a retry) start with their current glow
+    if (input.value) {
+        check();
+    }
+})();